@@ -6,13 +6,12 @@ import { graphqlFetch } from "../api/gateway";
 import { AuthHeaders } from "../types/auth";
 
 interface TimelineEntry {
-  event_id: string;
+  timeline_id: string;
   category: string;
   started_at: string;
   ended_at: string;
   confidence: number;
   source: string;
-  metadata?: Record<string, unknown>;
 }
 
 const TimelineScreen: React.FC = () => {
@@ -33,7 +32,7 @@ const TimelineScreen: React.FC = () => {
           query: `
             query Timeline($userId: ID!) {
               timeline(userId: $userId, limit: 25) {
-                event_id
+                timeline_id
                 category
                 started_at
                 ended_at
@@ -64,7 +63,7 @@ const TimelineScreen: React.FC = () => {
       <Text style={styles.title}>오늘의 타임라인</Text>
       <FlatList
         data={entries}
-        keyExtractor={(item) => item.event_id}
+        keyExtractor={(item) => item.timeline_id}
         refreshControl={
           <RefreshControl refreshing={loading} onRefresh={fetchTimeline} />
         }
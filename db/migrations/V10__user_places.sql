@@ -0,0 +1,31 @@
+-- 사용자 장소(지오펜스)와 장소 방문
+-- shape가 circle이면 center_lat/center_lng/radius_m, polygon이면 polygon([{lat, lng}, ...])을 사용한다.
+-- 방문은 같은 장소에서 연속으로 들어온 위치 이벤트를 하나로 이은 구간이며, 장소를 삭제하면 함께 삭제된다.
+
+CREATE TABLE IF NOT EXISTS user_places (
+    place_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id),
+    name TEXT NOT NULL,
+    shape TEXT NOT NULL,
+    center_lat DOUBLE PRECISION,
+    center_lng DOUBLE PRECISION,
+    radius_m DOUBLE PRECISION,
+    polygon JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS place_visits (
+    visit_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id),
+    place_id UUID NOT NULL REFERENCES user_places(place_id) ON DELETE CASCADE,
+    entered_at TIMESTAMPTZ NOT NULL,
+    exited_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_place_visits_user_entered
+    ON place_visits (user_id, entered_at);
+
+CREATE INDEX IF NOT EXISTS idx_timeline_entries_visit_id
+    ON timeline_entries ((geo_context->>'visit_id'));
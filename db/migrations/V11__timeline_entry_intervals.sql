@@ -0,0 +1,28 @@
+-- 타임라인 항목 구간 컬럼
+-- 타임라인 조회가 activity_events를 다시 계산하지 않고 timeline_entries의 병합 블록을 그대로 읽도록 구간과 대표 소스를 저장한다.
+-- 기존 항목은 source_event_ids의 이벤트 구간으로 채운다. 이벤트가 모두 삭제된 항목은 구간이 비어 조회에서 제외된다.
+
+ALTER TABLE timeline_entries
+    ADD COLUMN IF NOT EXISTS source TEXT,
+    ADD COLUMN IF NOT EXISTS started_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS ended_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+UPDATE timeline_entries t
+   SET started_at = e.started_at,
+       ended_at = e.ended_at,
+       source = e.source
+  FROM (
+       SELECT te.timeline_id,
+              MIN(a.timestamp_start) AS started_at,
+              MAX(a.timestamp_end) AS ended_at,
+              (ARRAY_AGG(a.source ORDER BY a.timestamp_start, a.event_id))[1] AS source
+         FROM timeline_entries te
+         JOIN activity_events a ON a.event_id = ANY(te.source_event_ids)
+        GROUP BY te.timeline_id
+  ) e
+ WHERE t.timeline_id = e.timeline_id
+   AND t.started_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_timeline_entries_user_started
+    ON timeline_entries (user_id, started_at DESC);
@@ -0,0 +1,10 @@
+-- 타임라인 키셋 페이지네이션 인덱스
+-- 조회는 (started_at, timeline_id) 내림차순 키셋으로 넘기므로 같은 순서의 인덱스로 바꾸고, 카테고리 필터용 인덱스를 추가한다.
+
+CREATE INDEX IF NOT EXISTS idx_timeline_entries_user_started_id
+    ON timeline_entries (user_id, started_at DESC, timeline_id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_timeline_entries_user_category_started_id
+    ON timeline_entries (user_id, category, started_at DESC, timeline_id DESC);
+
+DROP INDEX IF EXISTS idx_timeline_entries_user_started;
@@ -0,0 +1,8 @@
+-- 타임라인 블록 분류 결과
+-- 활동 분류 서비스가 반환한 분류 신뢰도, 근거, 모델 버전을 블록마다 저장한다.
+-- 분류 서비스 없이 규칙 기반 카테고리를 사용한 블록은 model_version이 'rules'이고 category_confidence가 비어 있다.
+
+ALTER TABLE timeline_entries
+    ADD COLUMN IF NOT EXISTS category_confidence NUMERIC,
+    ADD COLUMN IF NOT EXISTS rationale TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[],
+    ADD COLUMN IF NOT EXISTS model_version TEXT;
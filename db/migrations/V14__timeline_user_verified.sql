@@ -0,0 +1,18 @@
+-- 타임라인 항목 사용자 수정
+-- 사용자가 카테고리를 수정한 항목은 user_verified로 표시하고, 소비자가 블록을 다시 저장해도 카테고리와 신뢰도를 덮어쓰지 않는다.
+-- 기존 수정 기록(activity_feedback)은 가장 최근 수정을 항목의 카테고리로 옮긴다.
+
+ALTER TABLE timeline_entries
+    ADD COLUMN IF NOT EXISTS user_verified BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE timeline_entries t
+   SET category = f.new_category,
+       confidence = 1.0,
+       user_verified = TRUE
+  FROM (
+       SELECT DISTINCT ON (timeline_id) timeline_id, new_category
+         FROM activity_feedback
+        ORDER BY timeline_id, created_at DESC
+  ) f
+ WHERE t.timeline_id = f.timeline_id
+   AND NOT t.user_verified;
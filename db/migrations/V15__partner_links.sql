@@ -0,0 +1,15 @@
+-- 사용자가 연동한 외부 파트너
+-- 파트너 웹훅은 연동이 유효한(revoked_at이 없는) 사용자의 이벤트만 받는다.
+-- 연동을 해제해도 행은 revoked_at이 채워진 채로 남고, 다시 연동하면 linked_at이 갱신된다.
+
+CREATE TABLE IF NOT EXISTS partner_links (
+    user_id UUID NOT NULL REFERENCES users(id),
+    partner TEXT NOT NULL,
+    linked_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMPTZ,
+    PRIMARY KEY (user_id, partner)
+);
+
+CREATE INDEX IF NOT EXISTS idx_partner_links_active
+    ON partner_links (partner, user_id)
+    WHERE revoked_at IS NULL;
@@ -0,0 +1,24 @@
+-- 동의 철회에 따른 데이터 삭제(purge) 기록
+-- 수집 서비스가 activity_events를 삭제하는 트랜잭션에서 적재하고, 아웃박스 릴레이가 Kafka activity.purge로 발행한 뒤 sent_at을 기록한다.
+-- 타임라인 서비스는 이 메시지로 파생 데이터(타임라인 항목, 수정 기록, 장소 방문)를 정리하며, 늦게 도착한 삭제된 이벤트를 거를 때도 이 표를 조회한다.
+-- 발행이 끝난 행도 삭제 요청의 감사 기록으로 남긴다.
+
+CREATE TABLE IF NOT EXISTS consent_purges (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id),
+    kind TEXT NOT NULL,
+    sources TEXT[] NOT NULL,
+    purged BIGINT NOT NULL,
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_consent_purges_pending
+    ON consent_purges (next_attempt_at, id)
+    WHERE sent_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_consent_purges_user
+    ON consent_purges (user_id);
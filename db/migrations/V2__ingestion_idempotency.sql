@@ -0,0 +1,13 @@
+-- 수집 API 멱등 키 (Idempotency-Key 헤더 또는 클라이언트 event_id)
+
+CREATE TABLE IF NOT EXISTS ingestion_idempotency_keys (
+    user_id UUID NOT NULL,
+    idempotency_key TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    event_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ingestion_idempotency_keys_created_at
+    ON ingestion_idempotency_keys (created_at);
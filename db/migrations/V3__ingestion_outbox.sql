@@ -0,0 +1,22 @@
+-- 수집 이벤트 트랜잭셔널 아웃박스
+-- activity_events와 같은 트랜잭션에서 적재되고, 릴레이가 Kafka activity.raw로 발행한 뒤 sent_at을 기록한다.
+
+CREATE TABLE IF NOT EXISTS ingestion_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL REFERENCES activity_events(event_id) ON DELETE CASCADE,
+    message_key BYTEA NOT NULL,
+    payload BYTEA NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_ingestion_outbox_pending
+    ON ingestion_outbox (next_attempt_at, id)
+    WHERE sent_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_ingestion_outbox_sent_at
+    ON ingestion_outbox (sent_at)
+    WHERE sent_at IS NOT NULL;
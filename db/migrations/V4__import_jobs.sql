@@ -0,0 +1,19 @@
+-- Apple Health / Google Fit 백필 가져오기 작업
+
+CREATE TABLE IF NOT EXISTS import_jobs (
+    job_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id),
+    format TEXT NOT NULL,
+    status TEXT NOT NULL,
+    processed INT NOT NULL DEFAULT 0,
+    accepted INT NOT NULL DEFAULT 0,
+    duplicate INT NOT NULL DEFAULT 0,
+    rejected INT NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_jobs_user_id
+    ON import_jobs (user_id, created_at DESC);
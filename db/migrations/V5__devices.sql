@@ -0,0 +1,19 @@
+-- 연동 기기와 기기별 수집 토큰
+-- token_hash는 기기 토큰의 SHA-256(hex)이며 원문 토큰은 등록 응답에서 한 번만 노출된다.
+
+CREATE TABLE IF NOT EXISTS devices (
+    device_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id),
+    platform TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    token_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_devices_user_id
+    ON devices (user_id, created_at DESC);
+
+ALTER TABLE activity_events
+    ADD COLUMN IF NOT EXISTS device_id UUID REFERENCES devices(device_id);
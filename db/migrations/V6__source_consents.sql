@@ -0,0 +1,16 @@
+-- 사용자별 데이터 소스 수집 동의
+-- source는 소스 종류(screen_time, location, calendar, health)이며 같은 종류의 모든 소스 이름에 적용된다.
+-- 철회된 동의는 revoked_at이 채워진 채로 남아 감사 기록이 된다. 재동의 시 granted_at이 갱신된다.
+
+CREATE TABLE IF NOT EXISTS source_consents (
+    user_id UUID NOT NULL REFERENCES users(id),
+    source TEXT NOT NULL,
+    scope TEXT NOT NULL,
+    granted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, source)
+);
+
+CREATE INDEX IF NOT EXISTS idx_activity_events_user_source
+    ON activity_events (user_id, source);
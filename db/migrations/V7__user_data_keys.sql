@@ -0,0 +1,10 @@
+-- 메타데이터 필드 암호화용 사용자별 데이터 키
+-- wrapped_key는 마스터 키(AES-256-GCM)로 감싼 데이터 키(nonce || ciphertext)이다.
+-- 행을 삭제하면(crypto-shredding) 해당 사용자의 암호화된 필드는 복호화할 수 없다.
+
+CREATE TABLE IF NOT EXISTS user_data_keys (
+    key_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL UNIQUE REFERENCES users(id),
+    wrapped_key BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
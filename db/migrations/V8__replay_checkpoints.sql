@@ -0,0 +1,15 @@
+-- 이벤트 재발행(replay) 명령의 재개 지점
+-- params는 재개 시 같은 조건(사용자, 기간, 대상 토픽)인지 확인하기 위해 저장한다.
+
+CREATE TABLE IF NOT EXISTS replay_checkpoints (
+    name TEXT PRIMARY KEY,
+    params JSONB NOT NULL,
+    last_started_at TIMESTAMPTZ NOT NULL,
+    last_event_id UUID NOT NULL,
+    published BIGINT NOT NULL DEFAULT 0,
+    completed_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_activity_events_start_event
+    ON activity_events (timestamp_start, event_id);
@@ -0,0 +1,8 @@
+-- 타임라인 중복 제거 조회용 인덱스
+-- 겹치는 이벤트 조회는 사용자별 시작 시각 범위로, 기존 항목 병합은 source_event_ids 포함 여부로 찾는다.
+
+CREATE INDEX IF NOT EXISTS idx_activity_events_user_start
+    ON activity_events (user_id, timestamp_start);
+
+CREATE INDEX IF NOT EXISTS idx_timeline_entries_source_event_ids
+    ON timeline_entries USING GIN (source_event_ids);
@@ -4,9 +4,11 @@ dotenv.config();
 
 export interface ServiceEndpoints {
   timeline: string;
+  timelineInternal: string;
   label: string;
   socialFeed: string;
   ingestion: string;
+  ingestionInternal: string;
   community: string;
   billing: string;
 }
@@ -27,9 +29,11 @@ export function loadConfig(): GatewayConfig {
     port: env("GATEWAY_PORT", "4000"),
     endpoints: {
       timeline: env("TIMELINE_SERVICE_URL", "http://localhost:7002"),
+      timelineInternal: env("TIMELINE_INTERNAL_URL", "http://localhost:7102"),
       label: env("LABEL_SERVICE_URL", "http://localhost:7003"),
       socialFeed: env("SOCIAL_FEED_SERVICE_URL", "http://localhost:7004"),
       ingestion: env("INGESTION_SERVICE_URL", "http://localhost:7001"),
+      ingestionInternal: env("INGESTION_INTERNAL_URL", "http://localhost:7100"),
       community: env("COMMUNITY_SERVICE_URL", "http://localhost:7005"),
       billing: env("BILLING_SERVICE_URL", "http://localhost:7006")
     }
@@ -24,18 +24,23 @@ const typeDefs = /* GraphQL */ `
   }
 
   type TimelineEntry {
-    event_id: ID!
+    timeline_id: ID!
     user_id: ID!
     category: String!
+    user_verified: Boolean!
     started_at: String!
     ended_at: String!
     confidence: Float!
     source: String!
     geo_context: JSON
-    metadata: JSON
     source_event_ids: [ID!]!
   }
 
+  type TimelinePage {
+    entries: [TimelineEntry!]!
+    next_cursor: String
+  }
+
   type Label {
     id: ID!
     user_id: ID!
@@ -72,6 +77,87 @@ const typeDefs = /* GraphQL */ `
     verified_at: String
   }
 
+  type Device {
+    device_id: ID!
+    user_id: ID!
+    platform: String!
+    name: String!
+    created_at: String!
+    last_seen: String
+    revoked_at: String
+  }
+
+  type RegisteredDevice {
+    device: Device!
+    token: String!
+  }
+
+  type Consent {
+    user_id: ID!
+    source: String!
+    scope: String!
+    granted_at: String!
+    revoked_at: String
+    updated_at: String!
+  }
+
+  type ConsentRevocation {
+    source: String!
+    purged: Int!
+  }
+
+  type PartnerLink {
+    user_id: ID!
+    partner: String!
+    linked_at: String!
+    revoked_at: String
+  }
+
+  type GeoPoint {
+    lat: Float!
+    lng: Float!
+  }
+
+  type Place {
+    place_id: ID!
+    user_id: ID!
+    name: String!
+    shape: String!
+    center: GeoPoint
+    radius_m: Float
+    polygon: [GeoPoint!]
+    created_at: String!
+    updated_at: String!
+  }
+
+  type TimelineFeedback {
+    feedback_id: ID!
+    timeline_id: ID!
+    user_id: ID!
+    old_category: String!
+    new_category: String!
+    note: String
+    created_at: String!
+  }
+
+  input GeoPointInput {
+    lat: Float!
+    lng: Float!
+  }
+
+  input PlaceInput {
+    name: String!
+    shape: String!
+    center: GeoPointInput
+    radius_m: Float
+    polygon: [GeoPointInput!]
+  }
+
+  input RegisterDeviceInput {
+    platform: String!
+    name: String
+  }
+
   type MutationPayload {
     success: Boolean!
     message: String
@@ -117,11 +203,23 @@ const typeDefs = /* GraphQL */ `
   type Query {
     health: Health!
     timeline(userId: ID!, limit: Int): [TimelineEntry!]!
+    timelinePage(
+      userId: ID!
+      limit: Int
+      cursor: String
+      before: String
+      after: String
+      categories: [String!]
+    ): TimelinePage!
     labels(userId: ID!): [Label!]!
     feed(userId: ID!, limit: Int): [FeedItem!]!
     communities(includePro: Boolean): [Community!]!
     entitlement(userId: ID!): Entitlement
     viewerEntitlement: Entitlement
+    devices(includeRevoked: Boolean): [Device!]!
+    consents: [Consent!]!
+    partnerLinks: [PartnerLink!]!
+    places: [Place!]!
   }
 
   type Mutation {
@@ -129,6 +227,16 @@ const typeDefs = /* GraphQL */ `
     createFeedPost(input: CreateFeedPostInput!): FeedItem!
     createCommunity(input: CreateCommunityInput!): Community!
     joinCommunity(input: JoinCommunityInput!): Membership!
+    registerDevice(input: RegisterDeviceInput!): RegisteredDevice!
+    revokeDevice(deviceId: ID!): MutationPayload!
+    grantConsent(source: String!, scope: String): Consent!
+    revokeConsent(source: String!, purge: Boolean): ConsentRevocation!
+    linkPartner(partner: String!): PartnerLink!
+    unlinkPartner(partner: String!): MutationPayload!
+    createPlace(input: PlaceInput!): Place!
+    updatePlace(placeId: ID!, input: PlaceInput!): Place!
+    deletePlace(placeId: ID!): MutationPayload!
+    correctTimelineEntry(timelineId: ID!, category: String!, note: String): TimelineFeedback!
   }
 `;
 
@@ -148,6 +256,36 @@ const resolvers = {
       const url = `${endpoints.timeline}/v1/timeline/${args.userId}${
         search.size ? `?${search}` : ""
       }`;
+      const page = await fetchJSON(url);
+      return page?.entries ?? [];
+    },
+    timelinePage: async (
+      _: unknown,
+      args: {
+        userId: string;
+        limit?: number;
+        cursor?: string;
+        before?: string;
+        after?: string;
+        categories?: string[];
+      }
+    ) => {
+      const search = new URLSearchParams();
+      if (args.limit) {
+        search.set("limit", String(args.limit));
+      }
+      for (const key of ["cursor", "before", "after"] as const) {
+        const value = args[key];
+        if (value) {
+          search.set(key, value);
+        }
+      }
+      if (args.categories?.length) {
+        search.set("category", args.categories.join(","));
+      }
+      const url = `${endpoints.timeline}/v1/timeline/${args.userId}${
+        search.size ? `?${search}` : ""
+      }`;
       return fetchJSON(url);
     },
     labels: async (_: unknown, args: { userId: string }) => {
@@ -186,6 +324,32 @@ const resolvers = {
       const userId = requireAuthenticated(ctx);
       const url = `${endpoints.billing}/v1/entitlements/${userId}`;
       return fetchJSON(url);
+    },
+    devices: async (_: unknown, args: { includeRevoked?: boolean }, ctx: GraphQLContext) => {
+      const userId = requireAuthenticated(ctx);
+      const params = new URLSearchParams();
+      if (args.includeRevoked) {
+        params.set("include_revoked", "true");
+      }
+      const url = `${endpoints.ingestionInternal}/v1/devices/${encodeURIComponent(userId)}${
+        params.size ? `?${params}` : ""
+      }`;
+      return fetchJSON(url, { headers: { "x-user-id": userId } });
+    },
+    consents: async (_: unknown, __: unknown, ctx: GraphQLContext) => {
+      const userId = requireAuthenticated(ctx);
+      const url = `${endpoints.ingestionInternal}/v1/consents/${encodeURIComponent(userId)}`;
+      return fetchJSON(url, { headers: { "x-user-id": userId } });
+    },
+    partnerLinks: async (_: unknown, __: unknown, ctx: GraphQLContext) => {
+      const userId = requireAuthenticated(ctx);
+      const url = `${endpoints.ingestionInternal}/v1/partners/${encodeURIComponent(userId)}`;
+      return fetchJSON(url, { headers: { "x-user-id": userId } });
+    },
+    places: async (_: unknown, __: unknown, ctx: GraphQLContext) => {
+      const userId = requireAuthenticated(ctx);
+      const url = `${endpoints.timelineInternal}/v1/places/${encodeURIComponent(userId)}`;
+      return fetchJSON(url, { headers: { "x-user-id": userId } });
     }
   },
   Mutation: {
@@ -258,6 +422,90 @@ const resolvers = {
         headers: { "Content-Type": "application/json" },
         body: JSON.stringify(rest)
       });
+    },
+    registerDevice: async (_: unknown, args: { input: Record<string, unknown> }, ctx: GraphQLContext) => {
+      const userId = requireAuthenticated(ctx);
+      const url = `${endpoints.ingestionInternal}/v1/devices/${encodeURIComponent(userId)}`;
+      const { token, ...device } = await fetchJSON(url, {
+        method: "POST",
+        headers: { "Content-Type": "application/json", "x-user-id": userId },
+        body: JSON.stringify(args.input)
+      });
+      return { device, token };
+    },
+    revokeDevice: async (_: unknown, args: { deviceId: string }, ctx: GraphQLContext) => {
+      const userId = requireAuthenticated(ctx);
+      const url = `${endpoints.ingestionInternal}/v1/devices/${encodeURIComponent(userId)}/${encodeURIComponent(args.deviceId)}`;
+      await fetchJSON(url, { method: "DELETE", headers: { "x-user-id": userId } });
+      return { success: true };
+    },
+    grantConsent: async (_: unknown, args: { source: string; scope?: string }, ctx: GraphQLContext) => {
+      const userId = requireAuthenticated(ctx);
+      const url = `${endpoints.ingestionInternal}/v1/consents/${encodeURIComponent(userId)}/${encodeURIComponent(args.source)}`;
+      return fetchJSON(url, {
+        method: "PUT",
+        headers: { "Content-Type": "application/json", "x-user-id": userId },
+        body: JSON.stringify({ scope: args.scope ?? "" })
+      });
+    },
+    revokeConsent: async (_: unknown, args: { source: string; purge?: boolean }, ctx: GraphQLContext) => {
+      const userId = requireAuthenticated(ctx);
+      const url = `${endpoints.ingestionInternal}/v1/consents/${encodeURIComponent(userId)}/${encodeURIComponent(args.source)}${
+        args.purge ? "?purge=true" : ""
+      }`;
+      return fetchJSON(url, { method: "DELETE", headers: { "x-user-id": userId } });
+    },
+    linkPartner: async (_: unknown, args: { partner: string }, ctx: GraphQLContext) => {
+      const userId = requireAuthenticated(ctx);
+      const url = `${endpoints.ingestionInternal}/v1/partners/${encodeURIComponent(userId)}/${encodeURIComponent(args.partner)}`;
+      return fetchJSON(url, { method: "PUT", headers: { "x-user-id": userId } });
+    },
+    unlinkPartner: async (_: unknown, args: { partner: string }, ctx: GraphQLContext) => {
+      const userId = requireAuthenticated(ctx);
+      const url = `${endpoints.ingestionInternal}/v1/partners/${encodeURIComponent(userId)}/${encodeURIComponent(args.partner)}`;
+      await fetchJSON(url, { method: "DELETE", headers: { "x-user-id": userId } });
+      return { success: true };
+    },
+    createPlace: async (_: unknown, args: { input: Record<string, unknown> }, ctx: GraphQLContext) => {
+      const userId = requireAuthenticated(ctx);
+      const url = `${endpoints.timelineInternal}/v1/places/${encodeURIComponent(userId)}`;
+      return fetchJSON(url, {
+        method: "POST",
+        headers: { "Content-Type": "application/json", "x-user-id": userId },
+        body: JSON.stringify(args.input)
+      });
+    },
+    updatePlace: async (
+      _: unknown,
+      args: { placeId: string; input: Record<string, unknown> },
+      ctx: GraphQLContext
+    ) => {
+      const userId = requireAuthenticated(ctx);
+      const url = `${endpoints.timelineInternal}/v1/places/${encodeURIComponent(userId)}/${encodeURIComponent(args.placeId)}`;
+      return fetchJSON(url, {
+        method: "PUT",
+        headers: { "Content-Type": "application/json", "x-user-id": userId },
+        body: JSON.stringify(args.input)
+      });
+    },
+    deletePlace: async (_: unknown, args: { placeId: string }, ctx: GraphQLContext) => {
+      const userId = requireAuthenticated(ctx);
+      const url = `${endpoints.timelineInternal}/v1/places/${encodeURIComponent(userId)}/${encodeURIComponent(args.placeId)}`;
+      await fetchJSON(url, { method: "DELETE", headers: { "x-user-id": userId } });
+      return { success: true };
+    },
+    correctTimelineEntry: async (
+      _: unknown,
+      args: { timelineId: string; category: string; note?: string },
+      ctx: GraphQLContext
+    ) => {
+      const userId = requireAuthenticated(ctx);
+      const url = `${endpoints.timelineInternal}/v1/timeline/entries/${encodeURIComponent(args.timelineId)}/feedback`;
+      return fetchJSON(url, {
+        method: "POST",
+        headers: { "Content-Type": "application/json", "x-user-id": userId },
+        body: JSON.stringify({ category: args.category, note: args.note ?? "" })
+      });
     }
   }
 };
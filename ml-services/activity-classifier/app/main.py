@@ -6,6 +6,9 @@ from pydantic import BaseModel
 
 app = FastAPI(title="Daylog Activity Classifier", version="0.1.0")
 
+# 응답의 model_version. ONNX 모델을 로드하기 전까지는 규칙 기반 목업 버전이다.
+MODEL_VERSION = "mock-0.1.0"
+
 
 class ActivityEvent(BaseModel):
     user_id: str
@@ -19,6 +22,7 @@ class ClassificationResult(BaseModel):
     category: str
     confidence: float
     rationale: List[str]
+    model_version: str
 
 
 class ClassifyResponse(BaseModel):
@@ -46,5 +50,6 @@ async def classify(event: ActivityEvent):
             category=mock_category,
             confidence=0.75,
             rationale=rationale,
+            model_version=MODEL_VERSION,
         ),
     )
@@ -0,0 +1,19 @@
+module daylog/services/archiver
+
+go 1.21
+
+require (
+	daylog/services/common v0.0.0
+	github.com/gorilla/mux v1.8.1
+	github.com/segmentio/kafka-go v0.4.45
+	go.uber.org/zap v1.27.0
+)
+
+require (
+	github.com/kelseyhightower/envconfig v1.4.0 // indirect
+	github.com/klauspost/compress v1.15.9 // indirect
+	github.com/pierrec/lz4/v4 v4.1.15 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+)
+
+replace daylog/services/common => ../common
@@ -0,0 +1,114 @@
+package lake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound는 객체가 존재하지 않을 때 반환됩니다.
+var ErrNotFound = errors.New("object not found")
+
+// ErrExists는 불변 객체를 덮어쓰려 할 때 반환됩니다.
+var ErrExists = errors.New("object already exists")
+
+// ObjectStore는 레이크 파일을 저장하는 객체 저장소입니다. 키는 `/`로 구분된 상대 경로입니다.
+type ObjectStore interface {
+	// Put은 객체를 씁니다. overwrite가 false이면 이미 존재하는 키에 대해 ErrExists를 반환합니다.
+	Put(ctx context.Context, key string, body []byte, contentType string, overwrite bool) error
+	// Get은 객체를 읽습니다. 없으면 ErrNotFound를 반환합니다.
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+// LocalStore는 로컬 파일시스템 디렉터리를 객체 저장소로 사용합니다.
+type LocalStore struct {
+	root string
+}
+
+// NewLocalStore는 root 디렉터리를 사용하는 LocalStore를 생성합니다.
+func NewLocalStore(root string) (*LocalStore, error) {
+	if root == "" {
+		return nil, errors.New("local store root must not be empty")
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("create local store root: %w", err)
+	}
+	return &LocalStore{root: root}, nil
+}
+
+// Put은 임시 파일에 쓴 뒤 rename해 부분적으로 쓰인 파일이 노출되지 않도록 합니다.
+func (s *LocalStore) Put(ctx context.Context, key string, body []byte, contentType string, overwrite bool) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if !overwrite {
+		if _, err := os.Stat(path); err == nil {
+			return ErrExists
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // nolint:errcheck
+
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write %s: %w", key, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", key, err)
+	}
+	if !overwrite {
+		// rename은 대상을 덮어쓰므로 link로 존재 여부를 원자적으로 확인한다.
+		if err := os.Link(tmp.Name(), path); err != nil {
+			if errors.Is(err, os.ErrExist) {
+				return ErrExists
+			}
+			return fmt.Errorf("publish %s: %w", key, err)
+		}
+		return nil
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("publish %s: %w", key, err)
+	}
+	return nil
+}
+
+// Get은 객체를 읽습니다.
+func (s *LocalStore) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("open %s: %w", key, err)
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+func (s *LocalStore) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if filepath.IsAbs(clean) || clean == "." || strings.HasPrefix(clean, "..") {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	return filepath.Join(s.root, clean), nil
+}
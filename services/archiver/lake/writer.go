@@ -0,0 +1,260 @@
+package lake
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// manifestDir은 파티션 아래 매니페스트 객체를 두는 디렉터리입니다. 데이터 파일마다 매니페스트 객체가 하나씩 있다.
+const manifestDir = "_manifests"
+
+// Config는 파일 롤링 설정입니다.
+type Config struct {
+	// Prefix는 레이크 루트 경로입니다(예: activity/raw).
+	Prefix string
+	// MaxFileBytes는 압축 전 기준 파일 최대 크기입니다.
+	MaxFileBytes int64
+	// MaxFileAge는 파일을 열어 둘 수 있는 최대 시간입니다.
+	MaxFileAge time.Duration
+}
+
+// ManifestFile은 데이터 파일 하나의 메타데이터이며, `_manifests/<파일 이름>.json` 불변 객체로 기록됩니다.
+// 학습 작업은 파티션의 `_manifests/`에 매니페스트가 있는 파일만 읽습니다.
+// 파일마다 따로 쓰므로 여러 아카이버가 같은 파티션에 동시에 기록해도 서로의 항목을 덮어쓰지 않는다.
+type ManifestFile struct {
+	Key          string    `json:"key"`
+	Records      int       `json:"records"`
+	Bytes        int64     `json:"bytes"`
+	SHA256       string    `json:"sha256"`
+	MinStartedAt time.Time `json:"min_started_at"`
+	MaxStartedAt time.Time `json:"max_started_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// partitionBuffer는 한 파티션의 열린 파일(gzip NDJSON)입니다.
+type partitionBuffer struct {
+	partition string
+	buf       bytes.Buffer
+	gz        *gzip.Writer
+	records   int
+	rawBytes  int64
+	minStart  time.Time
+	maxStart  time.Time
+	openedAt  time.Time
+}
+
+// pendingFile은 닫혔지만 아직 저장소 업로드나 매니페스트 기록이 끝나지 않은 파일입니다.
+type pendingFile struct {
+	partition string
+	data      []byte
+	entry     ManifestFile
+}
+
+// Writer는 원시 이벤트를 `year=YYYY/month=MM/day=DD` 파티션별 gzip NDJSON 파일로 모아 객체 저장소에 씁니다.
+// Writer는 동시에 사용할 수 없습니다.
+type Writer struct {
+	store  ObjectStore
+	cfg    Config
+	logger *zap.SugaredLogger
+
+	buffers map[string]*partitionBuffer
+	pending []pendingFile
+}
+
+// NewWriter는 Writer를 생성합니다.
+func NewWriter(store ObjectStore, cfg Config, logger *zap.SugaredLogger) *Writer {
+	if logger == nil {
+		logger = zap.NewNop().Sugar()
+	}
+	return &Writer{
+		store:   store,
+		cfg:     cfg,
+		logger:  logger,
+		buffers: make(map[string]*partitionBuffer),
+	}
+}
+
+type recordHeader struct {
+	EventID   string    `json:"event_id"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Add는 원시 이벤트 JSON 한 건을 started_at(UTC) 날짜 파티션의 열린 파일에 추가합니다.
+// 어떤 파일이든 MaxFileBytes에 도달하면 full이 true입니다.
+func (w *Writer) Add(record []byte) (full bool, err error) {
+	var hdr recordHeader
+	if err := json.Unmarshal(record, &hdr); err != nil {
+		return false, fmt.Errorf("decode record: %w", err)
+	}
+	if hdr.StartedAt.IsZero() {
+		return false, errors.New("record has no started_at")
+	}
+
+	started := hdr.StartedAt.UTC()
+	partition := PartitionPath(started)
+	pb, ok := w.buffers[partition]
+	if !ok {
+		pb = &partitionBuffer{partition: partition, openedAt: time.Now()}
+		pb.gz = gzip.NewWriter(&pb.buf)
+		w.buffers[partition] = pb
+	}
+
+	line := bytes.TrimSpace(record)
+	if _, err := pb.gz.Write(line); err != nil {
+		return false, fmt.Errorf("compress record: %w", err)
+	}
+	if _, err := pb.gz.Write([]byte("\n")); err != nil {
+		return false, fmt.Errorf("compress record: %w", err)
+	}
+	pb.records++
+	pb.rawBytes += int64(len(line)) + 1
+	if pb.minStart.IsZero() || started.Before(pb.minStart) {
+		pb.minStart = started
+	}
+	if started.After(pb.maxStart) {
+		pb.maxStart = started
+	}
+
+	return pb.rawBytes >= w.cfg.MaxFileBytes, nil
+}
+
+// NextFlush는 가장 오래된 열린 파일이 MaxFileAge에 도달하는 시각을 반환합니다. 열린 파일이 없으면 0 값입니다.
+func (w *Writer) NextFlush() time.Time {
+	var next time.Time
+	for _, pb := range w.buffers {
+		due := pb.openedAt.Add(w.cfg.MaxFileAge)
+		if next.IsZero() || due.Before(next) {
+			next = due
+		}
+	}
+	if len(w.pending) > 0 && (next.IsZero() || time.Now().Before(next)) {
+		// 업로드에 실패한 파일은 다음 주기에 다시 시도한다.
+		next = time.Now().Add(time.Second)
+	}
+	return next
+}
+
+// Flush는 열린 파일을 모두 닫아 업로드하고 파일마다 매니페스트를 기록합니다.
+// nil을 반환하면 지금까지 Add된 모든 레코드가 저장소에 기록된 것이므로 소비 오프셋을 커밋해도 됩니다.
+// 실패한 파일은 보관했다가 다음 Flush에서 다시 업로드합니다.
+func (w *Writer) Flush(ctx context.Context) error {
+	partitions := make([]string, 0, len(w.buffers))
+	for partition := range w.buffers {
+		partitions = append(partitions, partition)
+	}
+	sort.Strings(partitions)
+
+	for _, partition := range partitions {
+		file, err := w.seal(w.buffers[partition])
+		if err != nil {
+			return err
+		}
+		delete(w.buffers, partition)
+		w.pending = append(w.pending, file)
+	}
+
+	remaining := w.pending[:0]
+	var firstErr error
+	for _, file := range w.pending {
+		if err := w.upload(ctx, file); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			remaining = append(remaining, file)
+			continue
+		}
+		w.logger.Infow("archived raw events",
+			"key", file.entry.Key,
+			"records", file.entry.Records,
+			"bytes", file.entry.Bytes,
+		)
+	}
+	w.pending = remaining
+	return firstErr
+}
+
+// seal은 열린 파일을 닫고 업로드할 데이터와 매니페스트 항목을 만듭니다.
+func (w *Writer) seal(pb *partitionBuffer) (pendingFile, error) {
+	if err := pb.gz.Close(); err != nil {
+		return pendingFile{}, fmt.Errorf("close gzip stream: %w", err)
+	}
+	suffix, err := randomSuffix()
+	if err != nil {
+		return pendingFile{}, err
+	}
+
+	now := time.Now().UTC()
+	data := pb.buf.Bytes()
+	sum := sha256.Sum256(data)
+	key := fmt.Sprintf("%s/%s/part-%s-%s.ndjson.gz", w.cfg.Prefix, pb.partition, now.Format("20060102T150405Z"), suffix)
+
+	return pendingFile{
+		partition: pb.partition,
+		data:      data,
+		entry: ManifestFile{
+			Key:          key,
+			Records:      pb.records,
+			Bytes:        int64(len(data)),
+			SHA256:       hex.EncodeToString(sum[:]),
+			MinStartedAt: pb.minStart,
+			MaxStartedAt: pb.maxStart,
+			CreatedAt:    now,
+		},
+	}, nil
+}
+
+// upload는 데이터 파일을 불변 객체로 쓴 뒤 그 파일의 매니페스트를 씁니다.
+// 매니페스트는 데이터 파일이 모두 쓰인 뒤에만 생기므로 읽는 쪽은 부분적으로 쓰인 파일을 보지 않는다.
+func (w *Writer) upload(ctx context.Context, file pendingFile) error {
+	err := w.store.Put(ctx, file.entry.Key, file.data, "application/x-ndjson", false)
+	if err != nil && !errors.Is(err, ErrExists) {
+		// ErrExists는 이전 시도에서 업로드가 끝났지만 매니페스트 기록에 실패한 경우이다.
+		return fmt.Errorf("upload %s: %w", file.entry.Key, err)
+	}
+	return w.writeManifest(ctx, file.partition, file.entry)
+}
+
+func (w *Writer) writeManifest(ctx context.Context, partition string, entry ManifestFile) error {
+	key := ManifestKey(w.cfg.Prefix, partition, entry.Key)
+
+	body, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode manifest: %w", err)
+	}
+	// 같은 데이터 파일의 매니페스트는 내용이 같으므로 이전 시도에서 이미 쓰였으면(ErrExists) 성공으로 본다.
+	if err := w.store.Put(ctx, key, body, "application/json", false); err != nil && !errors.Is(err, ErrExists) {
+		return fmt.Errorf("write manifest %s: %w", key, err)
+	}
+	return nil
+}
+
+// ManifestKey는 데이터 파일 dataKey의 매니페스트 객체 키(`<prefix>/<partition>/_manifests/<파일 이름>.json`)를 반환합니다.
+func ManifestKey(prefix, partition, dataKey string) string {
+	return prefix + "/" + partition + "/" + manifestDir + "/" + path.Base(dataKey) + ".json"
+}
+
+// PartitionPath는 UTC 날짜의 파티션 경로(`year=2024/month=01/day=05`)를 반환합니다.
+func PartitionPath(t time.Time) string {
+	t = t.UTC()
+	return fmt.Sprintf("year=%04d/month=%02d/day=%02d", t.Year(), int(t.Month()), t.Day())
+}
+
+func randomSuffix() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate file suffix: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"daylog/services/archiver/lake"
+	"daylog/services/common/config"
+	"daylog/services/common/logging"
+	"daylog/services/common/messaging"
+
+	"github.com/gorilla/mux"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+type server struct {
+	cfg    config.Config
+	logger *zap.SugaredLogger
+	router *mux.Router
+}
+
+func main() {
+	cfg := config.MustLoad("archiver")
+
+	logger, err := logging.Init(cfg.Service.Name, cfg.Log.Level)
+	if err != nil {
+		panic(err)
+	}
+	defer logging.Sync()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if !cfg.HasKafka() {
+		logger.Fatal("KAFKA_BROKERS must be set for archiver service")
+	}
+
+	store, err := newObjectStore(cfg.Archiver)
+	if err != nil {
+		logger.Fatalw("failed to initialise object store", "error", err)
+	}
+
+	consumer, err := messaging.NewConsumer(messaging.ConsumerConfig{
+		Brokers: cfg.Kafka.Brokers,
+		Topic:   cfg.Kafka.ActivityTopic,
+		GroupID: cfg.Archiver.GroupID,
+	}, logger)
+	if err != nil {
+		logger.Fatalw("failed to initialise kafka consumer", "error", err)
+	}
+	defer consumer.Close()
+
+	writer := lake.NewWriter(store, lake.Config{
+		Prefix:       cfg.Archiver.Prefix,
+		MaxFileBytes: cfg.Archiver.MaxFileBytes,
+		MaxFileAge:   cfg.Archiver.MaxFileAge,
+	}, logger)
+
+	loopDone := make(chan struct{})
+	go func() {
+		defer close(loopDone)
+		runArchiveLoop(ctx, logger, consumer, writer)
+	}()
+
+	srv := newServer(cfg, logger)
+	httpServer := &http.Server{
+		Addr:              cfg.Addr(),
+		Handler:           srv.router,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			logger.Errorw("failed to shutdown http server", "error", err)
+		}
+	}()
+
+	logger.Infow("archiver service listening", "addr", cfg.Addr(), "store", cfg.Archiver.Store, "prefix", cfg.Archiver.Prefix)
+	if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		logger.Fatalw("http server error", "error", err)
+	}
+
+	// 종료 전에 열린 파일을 업로드하고 오프셋을 커밋하도록 기다린다.
+	<-loopDone
+}
+
+func newObjectStore(cfg config.ArchiverConfig) (lake.ObjectStore, error) {
+	switch cfg.Store {
+	case "local":
+		return lake.NewLocalStore(cfg.LocalDir)
+	case "s3":
+		return lake.NewS3Store(lake.S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			PathStyle:       cfg.S3PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("unknown archiver store %q (expected local or s3)", cfg.Store)
+	}
+}
+
+// runArchiveLoop는 activity.raw를 소비해 레이크 파일로 기록합니다.
+// 오프셋은 해당 메시지가 담긴 파일이 모두 업로드된 뒤에만 커밋하므로 at-least-once이며,
+// 재시작 시 중복 레코드가 생길 수 있어 읽는 쪽은 event_id로 중복을 제거해야 합니다.
+func runArchiveLoop(ctx context.Context, logger *zap.SugaredLogger, consumer *messaging.Consumer, writer *lake.Writer) {
+	logger.Infow("starting archive loop")
+
+	// Kafka 파티션별 마지막으로 읽은 메시지. 커밋은 파티션마다 마지막 메시지 하나면 충분하다.
+	uncommitted := make(map[int]kafka.Message)
+
+	flush := func(ctx context.Context) {
+		if err := writer.Flush(ctx); err != nil {
+			logger.Errorw("failed to flush archive files", "error", err)
+			return
+		}
+		for partition, msg := range uncommitted {
+			if err := consumer.Commit(ctx, msg); err != nil {
+				logger.Errorw("failed to commit kafka message", "partition", partition, "error", err)
+				continue
+			}
+			delete(uncommitted, partition)
+		}
+	}
+
+	for {
+		fetchCtx, cancel := ctx, context.CancelFunc(func() {})
+		if next := writer.NextFlush(); !next.IsZero() {
+			fetchCtx, cancel = context.WithDeadline(ctx, next)
+		}
+		msg, err := consumer.Fetch(fetchCtx)
+		cancel()
+
+		switch {
+		case ctx.Err() != nil:
+			finalCtx, cancelFinal := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+			flush(finalCtx)
+			cancelFinal()
+			logger.Infow("archive loop stopped")
+			return
+		case errors.Is(err, context.DeadlineExceeded):
+			flush(ctx)
+			continue
+		case err != nil:
+			logger.Errorw("failed to fetch kafka message", "error", err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		full, err := writer.Add(msg.Value)
+		if err != nil {
+			logger.Warnw("skipping undecodable raw event", "partition", msg.Partition, "offset", msg.Offset, "error", err)
+		}
+		uncommitted[msg.Partition] = msg
+		if full {
+			flush(ctx)
+		}
+	}
+}
+
+func newServer(cfg config.Config, logger *zap.SugaredLogger) *server {
+	s := &server{
+		cfg:    cfg,
+		logger: logger,
+		router: mux.NewRouter(),
+	}
+
+	s.router.HandleFunc("/healthz", s.handleHealth).Methods(http.MethodGet)
+
+	return s
+}
+
+func (s *server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status":  "ok",
+		"service": s.cfg.Service.Name,
+		"time":    time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
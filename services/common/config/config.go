@@ -2,18 +2,23 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/kelseyhightower/envconfig"
 )
 
 // Config는 각 서비스에서 공통으로 사용하는 환경설정 구조체입니다.
 type Config struct {
-	Service  ServiceConfig
-	HTTP     HTTPConfig
-	Log      LogConfig
-	Postgres PostgresConfig
-	Kafka    KafkaConfig
-	Stripe   StripeConfig
+	Service    ServiceConfig
+	HTTP       HTTPConfig
+	Log        LogConfig
+	Postgres   PostgresConfig
+	Kafka      KafkaConfig
+	Stripe     StripeConfig
+	Ingestion  IngestionConfig
+	Encryption EncryptionConfig
+	Archiver   ArchiverConfig
+	Timeline   TimelineConfig
 }
 
 type ServiceConfig struct {
@@ -36,6 +41,8 @@ type KafkaConfig struct {
 	Brokers       []string `envconfig:"KAFKA_BROKERS" default:"localhost:9092"`
 	ActivityTopic string   `envconfig:"KAFKA_TOPIC_ACTIVITY_RAW" default:"activity.raw"`
 	GroupID       string   `envconfig:"KAFKA_CONSUMER_GROUP" default:"daylog-consumer"`
+	// PurgeTopic은 동의 철회로 원본 이벤트를 삭제했음을 알리는 토픽입니다. 타임라인이 파생 데이터를 정리한다.
+	PurgeTopic string `envconfig:"KAFKA_TOPIC_ACTIVITY_PURGE" default:"activity.purge"`
 }
 
 type StripeConfig struct {
@@ -43,6 +50,106 @@ type StripeConfig struct {
 	WebhookSecret string `envconfig:"STRIPE_WEBHOOK_SECRET"`
 }
 
+// IngestionConfig는 수집 서비스 전용 설정입니다.
+type IngestionConfig struct {
+	OutboxBatchSize    int           `envconfig:"INGESTION_OUTBOX_BATCH_SIZE" default:"200"`
+	OutboxPollInterval time.Duration `envconfig:"INGESTION_OUTBOX_POLL_INTERVAL" default:"500ms"`
+	OutboxMaxBackoff   time.Duration `envconfig:"INGESTION_OUTBOX_MAX_BACKOFF" default:"5m"`
+	OutboxRetention    time.Duration `envconfig:"INGESTION_OUTBOX_RETENTION" default:"168h"`
+	ImportDir          string        `envconfig:"INGESTION_IMPORT_DIR"`
+	ImportMaxBytes     int64         `envconfig:"INGESTION_IMPORT_MAX_BYTES" default:"2147483648"`
+	ImportConcurrency  int           `envconfig:"INGESTION_IMPORT_CONCURRENCY" default:"2"`
+	ImportBatchSize    int           `envconfig:"INGESTION_IMPORT_BATCH_SIZE" default:"500"`
+	RequireDeviceAuth  bool          `envconfig:"INGESTION_REQUIRE_DEVICE_AUTH" default:"true"`
+	RequireConsent     bool          `envconfig:"INGESTION_REQUIRE_CONSENT" default:"true"`
+	// PartnerSecrets는 `partner:현재키|이전키,...` 형식이며, PartnerFormats는 `partner:형식,...` 형식입니다.
+	PartnerSecrets            map[string]string `envconfig:"INGESTION_PARTNER_SECRETS"`
+	PartnerFormats            map[string]string `envconfig:"INGESTION_PARTNER_FORMATS"`
+	PartnerSignatureTolerance time.Duration     `envconfig:"INGESTION_PARTNER_SIGNATURE_TOLERANCE" default:"5m"`
+	// GRPCPort가 비어 있으면 gRPC 수집 서버를 띄우지 않습니다.
+	GRPCPort string `envconfig:"INGESTION_GRPC_PORT" default:"9090"`
+	// InternalPort는 게이트웨이만 접근하는 사용자 관리 API(기기, 동의, 데이터 키) 포트입니다. 비어 있으면 관리 API를 띄우지 않습니다.
+	InternalPort string `envconfig:"INGESTION_INTERNAL_PORT" default:"7100"`
+	// DefaultTimezone은 user_settings가 없는 사용자의 오프셋 없는 시각을 해석할 때 사용합니다.
+	DefaultTimezone    string        `envconfig:"INGESTION_DEFAULT_TIMEZONE" default:"Asia/Seoul"`
+	ClockSkewThreshold time.Duration `envconfig:"INGESTION_CLOCK_SKEW_THRESHOLD" default:"5m"`
+}
+
+// EncryptionConfig는 메타데이터 필드 암호화 설정입니다. MasterKeyFile이 비어 있으면 암호화를 사용하지 않습니다.
+// Fields는 `소스종류:경로` 목록이며 경로는 점으로 구분합니다(예: `location:geo_context.city`).
+type EncryptionConfig struct {
+	MasterKeyFile string        `envconfig:"ENCRYPTION_MASTER_KEY_FILE"`
+	Fields        []string      `envconfig:"ENCRYPTION_FIELDS" default:"location:latitude,location:longitude,location:address,location:place_name"`
+	KeyCacheTTL   time.Duration `envconfig:"ENCRYPTION_KEY_CACHE_TTL" default:"5m"`
+}
+
+// ArchiverConfig는 원시 이벤트 레이크 아카이버 설정입니다.
+type ArchiverConfig struct {
+	GroupID           string        `envconfig:"ARCHIVER_CONSUMER_GROUP" default:"daylog-archiver"`
+	Store             string        `envconfig:"ARCHIVER_STORE" default:"local"`
+	LocalDir          string        `envconfig:"ARCHIVER_LOCAL_DIR" default:"data/lake"`
+	Prefix            string        `envconfig:"ARCHIVER_PREFIX" default:"activity/raw"`
+	MaxFileBytes      int64         `envconfig:"ARCHIVER_MAX_FILE_BYTES" default:"134217728"`
+	MaxFileAge        time.Duration `envconfig:"ARCHIVER_MAX_FILE_AGE" default:"15m"`
+	S3Endpoint        string        `envconfig:"ARCHIVER_S3_ENDPOINT"`
+	S3Region          string        `envconfig:"ARCHIVER_S3_REGION" default:"us-east-1"`
+	S3Bucket          string        `envconfig:"ARCHIVER_S3_BUCKET"`
+	S3AccessKeyID     string        `envconfig:"ARCHIVER_S3_ACCESS_KEY_ID"`
+	S3SecretAccessKey string        `envconfig:"ARCHIVER_S3_SECRET_ACCESS_KEY"`
+	S3PathStyle       bool          `envconfig:"ARCHIVER_S3_PATH_STYLE" default:"true"`
+}
+
+// TimelineConfig는 타임라인 서비스 전용 설정입니다.
+type TimelineConfig struct {
+	// Dedup* 설정은 여러 기기가 보고한 같은 활동을 하나의 타임라인 항목으로 합치는 중복 제거 단계에 적용됩니다.
+	// DedupSourcePriority는 대표 이벤트를 고를 때 앞에 있는 소스를 우선합니다.
+	DedupEnabled        bool     `envconfig:"TIMELINE_DEDUP_ENABLED" default:"true"`
+	DedupOverlapRatio   float64  `envconfig:"TIMELINE_DEDUP_OVERLAP_RATIO" default:"0.7"`
+	DedupKinds          []string `envconfig:"TIMELINE_DEDUP_KINDS" default:"health,location,calendar"`
+	DedupSourcePriority []string `envconfig:"TIMELINE_DEDUP_SOURCE_PRIORITY" default:"apple_health,google_fit,health,ios_location,android_location,location,calendar"`
+	// Merge* 설정은 겹치거나 가까이 이어진 같은 카테고리 이벤트를 하나의 블록으로 합치는 병합 단계에 적용됩니다.
+	// MergeWindow는 이벤트 하나를 처리할 때 그보다 먼저 시작한 이벤트를 조회하는 범위입니다.
+	MergeGap             time.Duration `envconfig:"TIMELINE_MERGE_GAP" default:"5m"`
+	MergeWindow          time.Duration `envconfig:"TIMELINE_MERGE_WINDOW" default:"12h"`
+	MergeLocationRadiusM float64       `envconfig:"TIMELINE_MERGE_LOCATION_RADIUS_M" default:"150"`
+	// Classifier* 설정은 병합된 블록을 활동 분류 서비스로 분류하는 단계에 적용됩니다. ClassifierURL이 비어 있으면
+	// 규칙 기반 카테고리(metadata.category 또는 소스 종류)만 사용합니다.
+	ClassifierURL              string        `envconfig:"TIMELINE_CLASSIFIER_URL"`
+	ClassifierTimeout          time.Duration `envconfig:"TIMELINE_CLASSIFIER_TIMEOUT" default:"2s"`
+	ClassifierConcurrency      int           `envconfig:"TIMELINE_CLASSIFIER_CONCURRENCY" default:"8"`
+	ClassifierFailureThreshold int           `envconfig:"TIMELINE_CLASSIFIER_FAILURE_THRESHOLD" default:"5"`
+	ClassifierCooldown         time.Duration `envconfig:"TIMELINE_CLASSIFIER_COOLDOWN" default:"30s"`
+	// Consumer* 설정은 타임라인 소비자의 재시도 정책입니다. 일시적인 오류(DB 장애 등)는 ConsumerRetryBackoff부터
+	// 두 배씩 늘려 ConsumerMaxBackoff까지 기다리며 최대 ConsumerMaxAttempts번 처리하고, 그래도 실패하거나
+	// 다시 시도해도 성공할 수 없는 메시지(디코딩 실패 등)는 DLQTopic으로 보낸 뒤 커밋합니다.
+	ConsumerMaxAttempts  int           `envconfig:"TIMELINE_CONSUMER_MAX_ATTEMPTS" default:"5"`
+	ConsumerRetryBackoff time.Duration `envconfig:"TIMELINE_CONSUMER_RETRY_BACKOFF" default:"500ms"`
+	ConsumerMaxBackoff   time.Duration `envconfig:"TIMELINE_CONSUMER_MAX_BACKOFF" default:"30s"`
+	DLQTopic             string        `envconfig:"TIMELINE_DLQ_TOPIC" default:"activity.raw.dlq"`
+	// ConsumerWorkers는 이벤트를 동시에 처리하는 작업자 수입니다. 같은 user_id(메시지 키)의 이벤트는 같은 작업자가 순서대로 처리한다.
+	// 작업자는 밀린 메시지를 ConsumerBatchSize개까지 모아 한 트랜잭션으로 저장하고, 오프셋은 ConsumerCommitInterval마다
+	// 파티션별로 앞선 메시지가 모두 끝난 위치까지만 커밋합니다. 종료 시에는 가져온 메시지를 ConsumerDrainTimeout 동안 마저 처리한다.
+	ConsumerWorkers        int           `envconfig:"TIMELINE_CONSUMER_WORKERS" default:"8"`
+	ConsumerBatchSize      int           `envconfig:"TIMELINE_CONSUMER_BATCH_SIZE" default:"50"`
+	ConsumerCommitInterval time.Duration `envconfig:"TIMELINE_CONSUMER_COMMIT_INTERVAL" default:"1s"`
+	ConsumerDrainTimeout   time.Duration `envconfig:"TIMELINE_CONSUMER_DRAIN_TIMEOUT" default:"20s"`
+	// DLQRedriveGroup은 DLQ 메시지를 원래 토픽으로 다시 넣는 재처리(redrive)가 사용하는 컨슈머 그룹입니다.
+	// DLQRedriveWait만큼 새 메시지가 없으면 DLQ를 모두 비운 것으로 본다.
+	DLQRedriveGroup string        `envconfig:"TIMELINE_DLQ_REDRIVE_GROUP" default:"timeline-dlq-redrive"`
+	DLQRedriveWait  time.Duration `envconfig:"TIMELINE_DLQ_REDRIVE_WAIT" default:"5s"`
+	// PurgeGroup은 동의 철회 삭제(activity.purge)를 소비해 파생 데이터를 정리하는 컨슈머 그룹입니다.
+	PurgeGroup string `envconfig:"TIMELINE_PURGE_GROUP" default:"timeline-purge"`
+	// InternalPort는 외부에 노출하지 않는 운영 API(DLQ 재처리)와 게이트웨이 전용 사용자 API(장소, 카테고리 수정) 포트입니다.
+	// 비어 있으면 두 API를 모두 띄우지 않습니다.
+	InternalPort string `envconfig:"TIMELINE_INTERNAL_PORT" default:"7100"`
+	// FeedbackTopic은 사용자의 카테고리 수정을 학습 파이프라인(feedback-trainer)으로 보내는 토픽입니다.
+	FeedbackTopic string `envconfig:"TIMELINE_FEEDBACK_TOPIC" default:"activity.feedback"`
+	// DefaultTimezone은 user_settings.timezone이 없거나 잘못된 사용자의 하루 경계를 계산할 때 사용합니다.
+	DefaultTimezone string `envconfig:"TIMELINE_DEFAULT_TIMEZONE" default:"Asia/Seoul"`
+	// GeofenceVisitGap은 같은 장소의 위치 이벤트 사이 간격이 이 값 이하이면 하나의 방문으로 잇습니다.
+	GeofenceVisitGap time.Duration `envconfig:"TIMELINE_GEOFENCE_VISIT_GAP" default:"15m"`
+}
+
 // MustLoad는 환경변수를 읽어 Config를 반환하며, 실패 시 panic을 발생시킵니다.
 func MustLoad(serviceName string) Config {
 	cfg, err := Load(serviceName)
@@ -86,3 +193,8 @@ func (c Config) HasKafka() bool {
 func (c Config) HasStripeWebhook() bool {
 	return c.Stripe.WebhookSecret != ""
 }
+
+// HasEncryption은 메타데이터 필드 암호화용 마스터 키 파일이 설정되어 있는지 여부를 반환합니다.
+func (c Config) HasEncryption() bool {
+	return c.Encryption.MasterKeyFile != ""
+}
@@ -0,0 +1,198 @@
+// Package fieldcrypt는 이벤트 메타데이터의 지정 필드를 사용자별 데이터 키로 암호화하는 엔벨로프 암호화를 제공합니다.
+//
+// 데이터 키(AES-256-GCM)는 사용자마다 하나씩 생성되어 로컬 마스터 키로 감싼 채 user_data_keys에 저장됩니다.
+// 암호화된 필드 값은 `enc:v1:<key_id>:<base64url(nonce||ciphertext)>` 문자열로 대체되며,
+// 평문은 원래 값의 JSON 표현입니다. 데이터 키를 삭제(crypto-shredding)하면 해당 사용자의 암호문은 복호화할 수 없습니다.
+package fieldcrypt
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const ciphertextPrefix = "enc:v1:"
+
+// Encryptor는 메타데이터 필드를 암호화·복호화합니다.
+type Encryptor struct {
+	keys *KeyStore
+}
+
+// New는 KeyStore를 사용하는 Encryptor를 생성합니다.
+func New(keys *KeyStore) *Encryptor {
+	return &Encryptor{keys: keys}
+}
+
+// IsEncrypted는 값이 이 패키지가 만든 암호문인지 확인합니다.
+func IsEncrypted(v any) bool {
+	s, ok := v.(string)
+	return ok && strings.HasPrefix(s, ciphertextPrefix)
+}
+
+// EncryptFields는 paths(`address` 또는 `geo_context.city`처럼 점으로 구분)에 해당하는 값을 암호화한 새 메타데이터를 반환합니다.
+// 원본 맵은 변경하지 않으며, 존재하지 않거나 이미 암호화된 경로는 건너뜁니다.
+func (e *Encryptor) EncryptFields(ctx context.Context, userID string, metadata map[string]any, paths []string) (map[string]any, error) {
+	if len(paths) == 0 || len(metadata) == 0 {
+		return metadata, nil
+	}
+
+	out := cloneMap(metadata)
+	var (
+		keyID string
+		key   []byte
+	)
+	for _, path := range paths {
+		parent, leaf, ok := lookupParent(out, path)
+		if !ok {
+			continue
+		}
+		value, ok := parent[leaf]
+		if !ok || value == nil || IsEncrypted(value) {
+			continue
+		}
+
+		if key == nil {
+			var err error
+			keyID, key, err = e.keys.currentKey(ctx, userID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		sealed, err := seal(key, keyID, userID, path, value)
+		if err != nil {
+			return nil, fmt.Errorf("encrypt %s: %w", path, err)
+		}
+		parent[leaf] = sealed
+	}
+	return out, nil
+}
+
+// DecryptFields는 메타데이터 안의 모든 암호문을 찾아 원래 값으로 복원합니다(맵을 직접 수정).
+// 데이터 키가 파기된 필드는 메타데이터에서 제거하고, shredded 반환값을 true로 설정합니다.
+func (e *Encryptor) DecryptFields(ctx context.Context, userID string, metadata map[string]any) (shredded bool, err error) {
+	err = e.decryptMap(ctx, userID, "", metadata, &shredded)
+	return shredded, err
+}
+
+// Shred는 사용자의 데이터 키를 파기합니다. 키가 없었으면 false를 반환합니다.
+func (e *Encryptor) Shred(ctx context.Context, userID string) (bool, error) {
+	return e.keys.Shred(ctx, userID)
+}
+
+func (e *Encryptor) decryptMap(ctx context.Context, userID, prefix string, m map[string]any, shredded *bool) error {
+	for k, v := range m {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+
+		switch val := v.(type) {
+		case map[string]any:
+			if err := e.decryptMap(ctx, userID, path, val, shredded); err != nil {
+				return err
+			}
+		case string:
+			if !strings.HasPrefix(val, ciphertextPrefix) {
+				continue
+			}
+			plain, err := e.open(ctx, userID, path, val)
+			if errors.Is(err, ErrKeyShredded) {
+				delete(m, k)
+				*shredded = true
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("decrypt %s: %w", path, err)
+			}
+			m[k] = plain
+		}
+	}
+	return nil
+}
+
+func (e *Encryptor) open(ctx context.Context, userID, path, sealed string) (any, error) {
+	keyID, payload, ok := strings.Cut(strings.TrimPrefix(sealed, ciphertextPrefix), ":")
+	if !ok {
+		return nil, errors.New("malformed ciphertext")
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, fmt.Errorf("decode ciphertext: %w", err)
+	}
+
+	key, err := e.keys.keyByID(ctx, userID, keyID)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	n := aead.NonceSize()
+	if len(raw) < n {
+		return nil, errors.New("truncated ciphertext")
+	}
+	plain, err := aead.Open(nil, raw[:n], raw[n:], aad(userID, path))
+	if err != nil {
+		return nil, fmt.Errorf("open ciphertext: %w", err)
+	}
+
+	var value any
+	if err := json.Unmarshal(plain, &value); err != nil {
+		return nil, fmt.Errorf("decode plaintext: %w", err)
+	}
+	return value, nil
+}
+
+// seal은 값을 JSON으로 직렬화해 암호화합니다. 암호문을 다른 사용자·필드로 옮길 수 없도록 user_id와 경로를 AAD로 사용합니다.
+func seal(key []byte, keyID, userID, path string, value any) (string, error) {
+	plain, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, plain, aad(userID, path))
+	return ciphertextPrefix + keyID + ":" + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+func aad(userID, path string) []byte {
+	return []byte(userID + "|" + path)
+}
+
+// lookupParent는 점으로 구분된 경로의 부모 맵과 마지막 키를 찾습니다.
+func lookupParent(m map[string]any, path string) (map[string]any, string, bool) {
+	segments := strings.Split(path, ".")
+	for _, seg := range segments[:len(segments)-1] {
+		next, ok := m[seg].(map[string]any)
+		if !ok {
+			return nil, "", false
+		}
+		m = next
+	}
+	return m, segments[len(segments)-1], true
+}
+
+// cloneMap은 중첩 맵까지 복사합니다. 슬라이스 등 다른 값은 공유합니다.
+func cloneMap(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		if nested, ok := v.(map[string]any); ok {
+			out[k] = cloneMap(nested)
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,260 @@
+package fieldcrypt
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const keySize = 32 // AES-256
+
+// ErrKeyShredded는 사용자 데이터 키가 파기되어 암호문을 더 이상 복호화할 수 없을 때 반환됩니다.
+var ErrKeyShredded = errors.New("data key shredded")
+
+// LoadMasterKey는 마스터 키 파일을 읽습니다. 파일은 32바이트 원시 키이거나 그 base64/hex 문자열이어야 합니다.
+func LoadMasterKey(path string) ([]byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read master key: %w", err)
+	}
+	if len(raw) == keySize {
+		return raw, nil
+	}
+
+	text := strings.TrimSpace(string(raw))
+	if key, err := hex.DecodeString(text); err == nil && len(key) == keySize {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(text); err == nil && len(key) == keySize {
+		return key, nil
+	}
+	return nil, fmt.Errorf("master key must be %d bytes (raw, hex or base64)", keySize)
+}
+
+type cachedKey struct {
+	userID   string
+	key      []byte
+	loadedAt time.Time
+}
+
+// KeyStore는 user_data_keys 테이블에 마스터 키로 감싼 사용자별 데이터 키를 보관합니다.
+// 복호화된 데이터 키는 cacheTTL 동안만 메모리에 유지하므로, 파기 후 최대 cacheTTL이 지나면 모든 프로세스에서 읽을 수 없게 됩니다.
+type KeyStore struct {
+	pool     *pgxpool.Pool
+	master   cipher.AEAD
+	cacheTTL time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedKey // key_id → 데이터 키
+}
+
+// NewKeyStore는 마스터 키로 KeyStore를 생성합니다.
+func NewKeyStore(pool *pgxpool.Pool, masterKey []byte, cacheTTL time.Duration) (*KeyStore, error) {
+	master, err := newAEAD(masterKey)
+	if err != nil {
+		return nil, fmt.Errorf("init master key: %w", err)
+	}
+	return &KeyStore{
+		pool:     pool,
+		master:   master,
+		cacheTTL: cacheTTL,
+		cache:    make(map[string]cachedKey),
+	}, nil
+}
+
+// currentKey는 사용자의 현재 데이터 키를 반환하며, 없으면 새로 생성해 저장합니다.
+func (s *KeyStore) currentKey(ctx context.Context, userID string) (string, []byte, error) {
+	const selectQuery = `
+		SELECT key_id,
+		       wrapped_key
+		  FROM user_data_keys
+		 WHERE user_id = $1
+	`
+
+	var (
+		keyID   string
+		wrapped []byte
+	)
+	err := s.pool.QueryRow(ctx, selectQuery, userID).Scan(&keyID, &wrapped)
+	if errors.Is(err, pgx.ErrNoRows) {
+		keyID, wrapped, err = s.createKey(ctx, userID)
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("load data key: %w", err)
+	}
+
+	if key, ok := s.cached(keyID, userID); ok {
+		return keyID, key, nil
+	}
+	key, err := s.unwrap(userID, keyID, wrapped)
+	if err != nil {
+		return "", nil, err
+	}
+	s.store(keyID, userID, key)
+	return keyID, key, nil
+}
+
+// createKey는 새 데이터 키를 생성해 저장합니다. 동시에 다른 요청이 먼저 생성했다면 그 키를 반환합니다.
+func (s *KeyStore) createKey(ctx context.Context, userID string) (string, []byte, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", nil, fmt.Errorf("generate data key: %w", err)
+	}
+	keyID, err := newKeyID()
+	if err != nil {
+		return "", nil, err
+	}
+	wrapped, err := s.wrap(userID, keyID, key)
+	if err != nil {
+		return "", nil, err
+	}
+
+	const insert = `
+		INSERT INTO user_data_keys (
+			key_id,
+			user_id,
+			wrapped_key
+		) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		   SET user_id = EXCLUDED.user_id
+		RETURNING key_id, wrapped_key
+	`
+
+	var (
+		storedID      string
+		storedWrapped []byte
+	)
+	if err := s.pool.QueryRow(ctx, insert, keyID, userID, wrapped).Scan(&storedID, &storedWrapped); err != nil {
+		return "", nil, fmt.Errorf("insert data key: %w", err)
+	}
+	return storedID, storedWrapped, nil
+}
+
+// keyByID는 암호문에 기록된 key_id의 데이터 키를 반환합니다. 파기된 키이면 ErrKeyShredded를 반환합니다.
+func (s *KeyStore) keyByID(ctx context.Context, userID, keyID string) ([]byte, error) {
+	if key, ok := s.cached(keyID, userID); ok {
+		return key, nil
+	}
+
+	const query = `
+		SELECT wrapped_key
+		  FROM user_data_keys
+		 WHERE key_id = $1
+		   AND user_id = $2
+	`
+
+	var wrapped []byte
+	if err := s.pool.QueryRow(ctx, query, keyID, userID).Scan(&wrapped); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrKeyShredded
+		}
+		return nil, fmt.Errorf("load data key: %w", err)
+	}
+
+	key, err := s.unwrap(userID, keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	s.store(keyID, userID, key)
+	return key, nil
+}
+
+// Shred는 사용자의 데이터 키를 삭제합니다. 이후 해당 사용자의 기존 암호문은 복호화할 수 없습니다.
+func (s *KeyStore) Shred(ctx context.Context, userID string) (bool, error) {
+	const query = `
+		DELETE FROM user_data_keys
+		 WHERE user_id = $1
+	`
+
+	ct, err := s.pool.Exec(ctx, query, userID)
+	if err != nil {
+		return false, fmt.Errorf("delete data key: %w", err)
+	}
+
+	s.mu.Lock()
+	for id, entry := range s.cache {
+		if entry.userID == userID {
+			delete(s.cache, id)
+		}
+	}
+	s.mu.Unlock()
+
+	return ct.RowsAffected() > 0, nil
+}
+
+func (s *KeyStore) cached(keyID, userID string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.cache[keyID]
+	if !ok || entry.userID != userID {
+		return nil, false
+	}
+	if time.Since(entry.loadedAt) > s.cacheTTL {
+		delete(s.cache, keyID)
+		return nil, false
+	}
+	return entry.key, true
+}
+
+func (s *KeyStore) store(keyID, userID string, key []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache[keyID] = cachedKey{userID: userID, key: key, loadedAt: time.Now()}
+}
+
+// wrap은 데이터 키를 마스터 키로 암호화합니다. 다른 사용자 행으로 옮겨진 키를 거부하도록 user_id와 key_id를 AAD로 사용합니다.
+func (s *KeyStore) wrap(userID, keyID string, key []byte) ([]byte, error) {
+	nonce := make([]byte, s.master.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	return s.master.Seal(nonce, nonce, key, []byte(userID+"|"+keyID)), nil
+}
+
+func (s *KeyStore) unwrap(userID, keyID string, wrapped []byte) ([]byte, error) {
+	n := s.master.NonceSize()
+	if len(wrapped) < n {
+		return nil, fmt.Errorf("unwrap data key %s: truncated", keyID)
+	}
+	key, err := s.master.Open(nil, wrapped[:n], wrapped[n:], []byte(userID+"|"+keyID))
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key %s: %w", keyID, err)
+	}
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("key must be %d bytes", keySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// newKeyID는 UUIDv4 형식의 키 ID를 생성합니다.
+func newKeyID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate key id: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	h := hex.EncodeToString(b)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:], nil
+}
@@ -62,6 +62,38 @@ func (p *Producer) Publish(ctx context.Context, key []byte, value []byte) error
 	return nil
 }
 
+// Message는 PublishBatch로 전달할 키/값 쌍입니다. Headers는 선택입니다.
+type Message struct {
+	Key     []byte
+	Value   []byte
+	Headers []kafka.Header
+}
+
+// PublishBatch는 여러 메시지를 한 번의 WriteMessages 호출로 Kafka에 전달합니다.
+func (p *Producer) PublishBatch(ctx context.Context, messages []Message) error {
+	if p == nil || p.writer == nil {
+		return errors.New("producer is not initialized")
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	msgs := make([]kafka.Message, len(messages))
+	for i, m := range messages {
+		msgs[i] = kafka.Message{
+			Key:     m.Key,
+			Value:   m.Value,
+			Headers: m.Headers,
+			Time:    now,
+		}
+	}
+	if err := p.writer.WriteMessages(ctx, msgs...); err != nil {
+		return fmt.Errorf("write kafka messages: %w", err)
+	}
+	p.logger.Debugw("published kafka messages", "topic", p.topic, "count", len(msgs))
+	return nil
+}
+
 // Close는 writer 자원을 해제합니다.
 func (p *Producer) Close() error {
 	if p == nil || p.writer == nil {
@@ -124,12 +156,15 @@ func (c *Consumer) Fetch(ctx context.Context) (kafka.Message, error) {
 	return msg, nil
 }
 
-// Commit은 처리 완료된 메시지를 커밋합니다.
-func (c *Consumer) Commit(ctx context.Context, msg kafka.Message) error {
+// Commit은 처리 완료된 메시지를 커밋합니다. 여러 파티션의 메시지를 한 번에 커밋할 수 있습니다.
+func (c *Consumer) Commit(ctx context.Context, msgs ...kafka.Message) error {
 	if c == nil || c.reader == nil {
 		return errors.New("consumer is not initialized")
 	}
-	if err := c.reader.CommitMessages(ctx, msg); err != nil {
+	if len(msgs) == 0 {
+		return nil
+	}
+	if err := c.reader.CommitMessages(ctx, msgs...); err != nil {
 		return fmt.Errorf("commit kafka message: %w", err)
 	}
 	return nil
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"daylog/services/ingestion/validation"
+)
+
+const (
+	maxBatchItems     = 1000
+	maxBatchBodyBytes = 8 << 20 // 8 MiB
+)
+
+// 배치 항목 처리 상태
+const (
+	batchStatusAccepted  = "accepted"
+	batchStatusDuplicate = "duplicate"
+	batchStatusRejected  = "rejected"
+)
+
+type batchItemResult struct {
+	Index   int                     `json:"index"`
+	Status  string                  `json:"status"`
+	EventID string                  `json:"event_id,omitempty"`
+	Reason  string                  `json:"reason,omitempty"`
+	Fields  []validation.FieldError `json:"fields,omitempty"`
+}
+
+type batchResponse struct {
+	Accepted  int               `json:"accepted"`
+	Duplicate int               `json:"duplicate"`
+	Rejected  int               `json:"rejected"`
+	Results   []batchItemResult `json:"results"`
+}
+
+// handleBatchIngestion은 JSON 배열 또는 NDJSON으로 전달된 이벤트 묶음을 수집합니다.
+// 항목마다 검증하고, 통과한 항목은 아웃박스와 함께 한 트랜잭션으로 저장합니다.
+func (s *server) handleBatchIngestion(w http.ResponseWriter, r *http.Request) {
+	body := http.MaxBytesReader(w, r.Body, maxBatchBodyBytes)
+	defer body.Close()
+
+	rawItems, err := decodeBatch(r.Header.Get("Content-Type"), body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if len(rawItems) == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "batch is empty"})
+		return
+	}
+	if len(rawItems) > maxBatchItems {
+		writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{
+			"error": fmt.Sprintf("batch exceeds %d items", maxBatchItems),
+		})
+		return
+	}
+
+	results := make([]batchItemResult, len(rawItems))
+	items := make([]ingestItem, 0, len(rawItems))
+	indexes := make([]int, 0, len(rawItems))
+
+	for i, raw := range rawItems {
+		results[i] = batchItemResult{Index: i}
+
+		var payload activityEvent
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			results[i].Status = batchStatusRejected
+			results[i].Reason = "invalid payload"
+			continue
+		}
+		if err := bindDevice(r.Context(), &payload); err != nil {
+			results[i].Status = batchStatusRejected
+			results[i].Reason = err.Error()
+			continue
+		}
+
+		// 배치 항목은 event_id를 멱등 키로 사용한다.
+		items = append(items, ingestItem{Payload: payload, Key: payload.EventID})
+		indexes = append(indexes, i)
+	}
+
+	ingested, err := s.ingest(r.Context(), items)
+	if err != nil {
+		s.logger.Errorw("failed to persist activity event batch", "error", err, "count", len(items))
+		writeIngestError(w, err)
+		return
+	}
+
+	resp := batchResponse{Results: make([]batchItemResult, 0, len(rawItems))}
+	next := 0
+	for i := range rawItems {
+		if next < len(indexes) && indexes[next] == i {
+			resp.add(i, ingested[next])
+			next++
+			continue
+		}
+		resp.Results = append(resp.Results, results[i])
+		resp.Rejected++
+	}
+
+	writeJSON(w, http.StatusAccepted, resp)
+}
+
+// add는 수집 결과 하나를 응답에 추가하고 상태별 카운터를 갱신합니다.
+func (b *batchResponse) add(index int, res ingestResult) {
+	item := batchItemResult{
+		Index:   index,
+		EventID: res.EventID,
+		Reason:  res.Reason,
+		Fields:  res.Fields,
+	}
+	switch res.Status {
+	case ingestStatusAccepted:
+		item.Status = batchStatusAccepted
+		b.Accepted++
+	case ingestStatusDuplicate:
+		item.Status = batchStatusDuplicate
+		b.Duplicate++
+	default:
+		item.Status = batchStatusRejected
+		b.Rejected++
+	}
+	b.Results = append(b.Results, item)
+}
+
+// decodeBatch는 요청 본문을 항목별 원시 JSON으로 분리합니다.
+// Content-Type이 application/x-ndjson(또는 application/jsonl)이면 줄 단위로, 그 외에는 JSON 배열로 해석합니다.
+func decodeBatch(contentType string, body io.Reader) ([]json.RawMessage, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch mediaType {
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		var items []json.RawMessage
+		scanner := bufio.NewScanner(body)
+		scanner.Buffer(make([]byte, 64*1024), maxBatchBodyBytes)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			items = append(items, json.RawMessage(append([]byte(nil), line...)))
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("invalid ndjson payload: %w", err)
+		}
+		return items, nil
+	default:
+		var items []json.RawMessage
+		if err := json.NewDecoder(body).Decode(&items); err != nil {
+			return nil, errors.New("payload must be a JSON array of events")
+		}
+		return items, nil
+	}
+}
@@ -0,0 +1,306 @@
+// replay는 activity_events를 조회해 Kafka로 다시 발행하는 관리 명령입니다.
+// 타임라인 소비자 버그 수정 후 기록을 다시 처리하거나, 새 소비자를 별도 토픽으로 병행 검증할 때 사용합니다.
+//
+//	go run ./cmd/replay -users <uuid,...> -since 2024-01-01T00:00:00Z -until 2024-02-01T00:00:00Z \
+//	    -topic activity.raw.rebuild -rate 500 -checkpoint rebuild-2024-01
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"daylog/services/common/config"
+	"daylog/services/common/db"
+	"daylog/services/common/logging"
+	"daylog/services/common/messaging"
+	"daylog/services/ingestion/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// activityEvent는 수집 서비스가 activity.raw에 발행하는 메시지 형식입니다(ingestion의 activityEvent와 동일).
+type activityEvent struct {
+	EventID   string                 `json:"event_id,omitempty"`
+	UserID    string                 `json:"user_id"`
+	DeviceID  string                 `json:"device_id,omitempty"`
+	Source    string                 `json:"source"`
+	StartedAt time.Time              `json:"started_at"`
+	EndedAt   time.Time              `json:"ended_at"`
+	Metadata  map[string]interface{} `json:"metadata"`
+}
+
+type options struct {
+	users      []string
+	since      time.Time
+	until      time.Time
+	topic      string
+	rate       float64
+	batchSize  int
+	dryRun     bool
+	checkpoint string
+	reset      bool
+}
+
+// checkpointParams는 재개 시 이전 실행과 조건이 같은지 확인하는 데 사용됩니다.
+type checkpointParams struct {
+	Users []string  `json:"users"`
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+	Topic string    `json:"topic"`
+}
+
+func main() {
+	cfg := config.MustLoad("ingestion-replay")
+
+	logger, err := logging.Init(cfg.Service.Name, cfg.Log.Level)
+	if err != nil {
+		panic(err)
+	}
+	defer logging.Sync()
+
+	opts, err := parseFlags(cfg, os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if !cfg.HasPostgres() {
+		logger.Fatal("POSTGRES_URI must be set for replay")
+	}
+	pool, err := db.NewPool(ctx, cfg.Postgres.URI)
+	if err != nil {
+		logger.Fatalw("failed to create postgres pool", "error", err)
+	}
+	defer pool.Close()
+	repo := repository.NewEventRepository(pool)
+
+	var producer *messaging.Producer
+	if !opts.dryRun {
+		if !cfg.HasKafka() {
+			logger.Fatal("KAFKA_BROKERS must be set unless -dry-run is used")
+		}
+		producer, err = messaging.NewProducer(cfg.Kafka.Brokers, opts.topic, logger)
+		if err != nil {
+			logger.Fatalw("failed to initialise kafka producer", "error", err)
+		}
+		defer producer.Close()
+	}
+
+	published, err := run(ctx, logger, repo, producer, opts)
+	if err != nil {
+		logger.Fatalw("replay failed", "error", err, "published", published)
+	}
+	logger.Infow("replay finished", "published", published, "dry_run", opts.dryRun, "topic", opts.topic)
+}
+
+func parseFlags(cfg config.Config, args []string) (options, error) {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	var (
+		opts  options
+		users string
+		since string
+		until string
+	)
+	fs.StringVar(&users, "users", "", "comma-separated user IDs (empty: all users)")
+	fs.StringVar(&since, "since", "", "replay events with started_at >= since (RFC3339)")
+	fs.StringVar(&until, "until", "", "replay events with started_at < until (RFC3339)")
+	fs.StringVar(&opts.topic, "topic", cfg.Kafka.ActivityTopic, "target Kafka topic")
+	fs.Float64Var(&opts.rate, "rate", 200, "maximum events per second (0: unlimited)")
+	fs.IntVar(&opts.batchSize, "batch", 500, "events per query and publish batch")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "count matching events without publishing or saving checkpoints")
+	fs.StringVar(&opts.checkpoint, "checkpoint", "", "checkpoint name to resume from and save progress to")
+	fs.BoolVar(&opts.reset, "reset", false, "discard the existing checkpoint and start over")
+	if err := fs.Parse(args); err != nil {
+		return options{}, err
+	}
+
+	for _, id := range strings.Split(users, ",") {
+		if id = strings.TrimSpace(id); id == "" {
+			continue
+		}
+		if _, err := uuid.Parse(id); err != nil {
+			return options{}, fmt.Errorf("invalid user id %q", id)
+		}
+		opts.users = append(opts.users, id)
+	}
+
+	var err error
+	if since != "" {
+		if opts.since, err = time.Parse(time.RFC3339, since); err != nil {
+			return options{}, fmt.Errorf("since must be RFC3339: %w", err)
+		}
+	}
+	if until != "" {
+		if opts.until, err = time.Parse(time.RFC3339, until); err != nil {
+			return options{}, fmt.Errorf("until must be RFC3339: %w", err)
+		}
+	}
+	if !opts.since.IsZero() && !opts.until.IsZero() && !opts.until.After(opts.since) {
+		return options{}, fmt.Errorf("until must be after since")
+	}
+	if opts.topic == "" {
+		return options{}, fmt.Errorf("topic must not be empty")
+	}
+	if opts.batchSize <= 0 || opts.rate < 0 {
+		return options{}, fmt.Errorf("batch must be positive and rate must not be negative")
+	}
+	return opts, nil
+}
+
+// run은 조건에 맞는 이벤트를 배치 단위로 발행하고, 배치마다 재개 지점을 저장합니다.
+// 발행과 재개 지점 저장 사이에 중단되면 마지막 배치가 다시 발행될 수 있으므로 소비자는 event_id로 멱등해야 합니다.
+func run(ctx context.Context, logger *zap.SugaredLogger, repo *repository.EventRepository, producer *messaging.Producer, opts options) (int64, error) {
+	filter := repository.ReplayFilter{UserIDs: opts.users, Since: opts.since.UTC(), Until: opts.until.UTC()}
+	params, err := json.Marshal(checkpointParams{Users: opts.users, Since: filter.Since, Until: filter.Until, Topic: opts.topic})
+	if err != nil {
+		return 0, fmt.Errorf("marshal checkpoint params: %w", err)
+	}
+
+	var (
+		cursor    *repository.ReplayCursor
+		published int64
+	)
+	if opts.checkpoint != "" && !opts.dryRun {
+		if opts.reset {
+			if err := repo.DeleteReplayCheckpoint(ctx, opts.checkpoint); err != nil {
+				return 0, err
+			}
+		}
+		cp, err := repo.GetReplayCheckpoint(ctx, opts.checkpoint)
+		if err != nil {
+			return 0, err
+		}
+		if cp != nil {
+			if !sameParams(cp.Params, params) {
+				return 0, fmt.Errorf("checkpoint %q was created with different parameters (use -reset to start over)", opts.checkpoint)
+			}
+			if cp.CompletedAt != nil {
+				logger.Infow("checkpoint already completed", "checkpoint", opts.checkpoint, "published", cp.Published)
+				return cp.Published, nil
+			}
+			cursor, published = &cp.Cursor, cp.Published
+			logger.Infow("resuming replay", "checkpoint", opts.checkpoint, "after", cp.Cursor.StartedAt, "published", published)
+		}
+	}
+
+	start := time.Now()
+	var sent int64 // 이번 실행에서 발행한 수(속도 제한 기준)
+	for {
+		events, err := repo.ListEventsForReplay(ctx, filter, cursor, opts.batchSize)
+		if err != nil {
+			return published, err
+		}
+		if len(events) == 0 {
+			break
+		}
+
+		if !opts.dryRun {
+			messages, err := toMessages(events)
+			if err != nil {
+				return published, err
+			}
+			if err := producer.PublishBatch(ctx, messages); err != nil {
+				return published, err
+			}
+		}
+
+		last := events[len(events)-1]
+		cursor = &repository.ReplayCursor{StartedAt: last.TimestampStart, EventID: last.EventID}
+		published += int64(len(events))
+		sent += int64(len(events))
+
+		if opts.checkpoint != "" && !opts.dryRun {
+			if err := repo.SaveReplayCheckpoint(ctx, repository.ReplayCheckpoint{
+				Name:      opts.checkpoint,
+				Params:    params,
+				Cursor:    *cursor,
+				Published: published,
+			}, false); err != nil {
+				return published, err
+			}
+		}
+		logger.Infow("replay progress", "published", published, "through", last.TimestampStart, "dry_run", opts.dryRun)
+
+		if len(events) < opts.batchSize {
+			break
+		}
+		if err := pace(ctx, start, sent, opts.rate); err != nil {
+			return published, err
+		}
+	}
+
+	if opts.checkpoint != "" && !opts.dryRun && cursor != nil {
+		if err := repo.SaveReplayCheckpoint(ctx, repository.ReplayCheckpoint{
+			Name:      opts.checkpoint,
+			Params:    params,
+			Cursor:    *cursor,
+			Published: published,
+		}, true); err != nil {
+			return published, err
+		}
+	}
+	return published, nil
+}
+
+// pace는 시작 이후 평균 발행 속도가 rate(초당 이벤트)를 넘지 않도록 대기합니다.
+func pace(ctx context.Context, start time.Time, sent int64, rate float64) error {
+	if rate <= 0 {
+		return nil
+	}
+	wait := time.Until(start.Add(time.Duration(float64(sent) / rate * float64(time.Second))))
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func toMessages(events []repository.Event) ([]messaging.Message, error) {
+	messages := make([]messaging.Message, 0, len(events))
+	for _, e := range events {
+		metadata := e.Metadata
+		if metadata == nil {
+			metadata = map[string]interface{}{}
+		}
+		value, err := json.Marshal(activityEvent{
+			EventID:   e.EventID,
+			UserID:    e.UserID,
+			DeviceID:  e.DeviceID,
+			Source:    e.Source,
+			StartedAt: e.TimestampStart,
+			EndedAt:   e.TimestampEnd,
+			Metadata:  metadata,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("marshal event %s: %w", e.EventID, err)
+		}
+		messages = append(messages, messaging.Message{Key: []byte(e.UserID), Value: value})
+	}
+	return messages, nil
+}
+
+func sameParams(a, b json.RawMessage) bool {
+	var x, y checkpointParams
+	if json.Unmarshal(a, &x) != nil || json.Unmarshal(b, &y) != nil {
+		return false
+	}
+	return strings.Join(x.Users, ",") == strings.Join(y.Users, ",") &&
+		x.Since.Equal(y.Since) && x.Until.Equal(y.Until) && x.Topic == y.Topic
+}
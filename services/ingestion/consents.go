@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"daylog/services/ingestion/repository"
+	"daylog/services/ingestion/validation"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// 동의 범위. collect는 동의 시각 이후에 시작된 이벤트만, history는 그 이전의 과거 데이터(가져오기, 백필)까지 수집합니다.
+const (
+	consentScopeCollect = "collect"
+	consentScopeHistory = "history"
+)
+
+// defaultConsentScope는 scope를 지정하지 않은 동의에 기록되는 범위입니다.
+const defaultConsentScope = consentScopeCollect
+
+type grantConsentRequest struct {
+	Scope string `json:"scope"`
+}
+
+type revokeConsentResponse struct {
+	Source string `json:"source"`
+	Purged int64  `json:"purged"`
+}
+
+// consentSet은 한 번의 수집 호출 동안 사용하는 사용자별 유효 동의(소스 종류 → 동의)입니다.
+// nil이면 동의 검사를 하지 않습니다.
+type consentSet map[string]map[string]repository.Consent
+
+// loadConsents는 수집 항목에 등장하는 사용자의 유효 동의를 한 번에 조회합니다.
+func (s *server) loadConsents(ctx context.Context, items []ingestItem) (consentSet, error) {
+	if !s.cfg.Ingestion.RequireConsent || s.repo == nil {
+		return nil, nil
+	}
+
+	userIDs := ingestUserIDs(items)
+	consents := consentSet{}
+	if len(userIDs) == 0 {
+		return consents, nil
+	}
+
+	active, err := s.repo.ActiveConsents(ctx, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("load consents: %w", err)
+	}
+	for userID, kinds := range active {
+		consents[userID] = kinds
+	}
+	return consents, nil
+}
+
+// check는 이벤트가 수집 동의 범위 안에 있는지 확인하고, 아니면 거절 사유를 반환합니다.
+// 동의가 없는 소스 종류는 거절하고, history 범위가 아니면 동의 부여 이전에 시작된 이벤트도 거절합니다.
+func (c consentSet) check(registry *validation.Registry, payload activityEvent) string {
+	if c == nil {
+		return ""
+	}
+	kind, _ := registry.Kind(payload.Source)
+	consent, ok := c[payload.UserID][kind]
+	if !ok {
+		return fmt.Sprintf("no consent for %s data", kind)
+	}
+	if consent.Scope != consentScopeHistory && payload.StartedAt.Before(consent.GrantedAt) {
+		return fmt.Sprintf("started_at precedes %s consent granted at %s (grant %q scope to collect past data)",
+			kind, consent.GrantedAt.UTC().Format(time.RFC3339), consentScopeHistory)
+	}
+	return ""
+}
+
+// handleGrantConsent는 사용자의 소스 종류별 수집 동의를 기록합니다.
+func (s *server) handleGrantConsent(w http.ResponseWriter, r *http.Request) {
+	userID, kind, ok := s.consentVars(w, r)
+	if !ok {
+		return
+	}
+
+	var req grantConsentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+	req.Scope = strings.TrimSpace(req.Scope)
+	if req.Scope == "" {
+		req.Scope = defaultConsentScope
+	}
+	if req.Scope != consentScopeCollect && req.Scope != consentScopeHistory {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("scope must be %s or %s", consentScopeCollect, consentScopeHistory),
+		})
+		return
+	}
+
+	consent, err := s.repo.GrantConsent(r.Context(), userID, kind, req.Scope)
+	if err != nil {
+		s.logger.Errorw("failed to grant consent", "error", err, "user_id", userID, "source", kind)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to grant consent"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, consent)
+}
+
+// handleListConsents는 사용자의 동의 기록을 반환합니다.
+func (s *server) handleListConsents(w http.ResponseWriter, r *http.Request) {
+	if s.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "consents require postgres"})
+		return
+	}
+
+	userID := mux.Vars(r)["userId"]
+	if _, err := uuid.Parse(userID); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "userId must be a UUID"})
+		return
+	}
+
+	consents, err := s.repo.ListConsents(r.Context(), userID)
+	if err != nil {
+		s.logger.Errorw("failed to list consents", "error", err, "user_id", userID)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to list consents"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, consents)
+}
+
+// handleRevokeConsent는 동의를 철회합니다. purge=true이면 해당 종류의 기존 activity_events도 삭제합니다.
+func (s *server) handleRevokeConsent(w http.ResponseWriter, r *http.Request) {
+	userID, kind, ok := s.consentVars(w, r)
+	if !ok {
+		return
+	}
+
+	var purgeSources []string
+	if r.URL.Query().Get("purge") == "true" {
+		purgeSources = s.validator.SourcesOfKind(kind)
+	}
+
+	found, purged, err := s.repo.RevokeConsent(r.Context(), userID, kind, purgeSources)
+	if err != nil {
+		s.logger.Errorw("failed to revoke consent", "error", err, "user_id", userID, "source", kind)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to revoke consent"})
+		return
+	}
+	if !found {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "consent not found"})
+		return
+	}
+	if purged > 0 {
+		s.logger.Infow("purged activity events after consent revocation", "user_id", userID, "source", kind, "count", purged)
+	}
+
+	writeJSON(w, http.StatusOK, revokeConsentResponse{Source: kind, Purged: purged})
+}
+
+// consentVars는 경로의 userId와 소스 종류를 검증합니다. 동의는 소스 종류 단위로 관리합니다.
+func (s *server) consentVars(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	if s.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "consents require postgres"})
+		return "", "", false
+	}
+
+	vars := mux.Vars(r)
+	userID, kind := vars["userId"], vars["source"]
+	if _, err := uuid.Parse(userID); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "userId must be a UUID"})
+		return "", "", false
+	}
+	if !s.validator.IsKind(kind) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("source must be one of %s, %s, %s, %s",
+				validation.KindScreenTime, validation.KindLocation, validation.KindCalendar, validation.KindHealth),
+		})
+		return "", "", false
+	}
+	return userID, kind, true
+}
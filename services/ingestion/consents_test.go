@@ -0,0 +1,106 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"daylog/services/ingestion/importer"
+	"daylog/services/ingestion/repository"
+	"daylog/services/ingestion/validation"
+)
+
+const consentTestUser = "00000000-0000-0000-0000-000000000001"
+
+// 동의 부여(2024-06-01) 이전의 일정 하나와 이후의 일정 하나.
+const consentTestCalendar = `BEGIN:VCALENDAR
+VERSION:2.0
+BEGIN:VEVENT
+UID:past@example.com
+SUMMARY:지난 회의
+DTSTART:20240110T090000Z
+DTEND:20240110T100000Z
+END:VEVENT
+BEGIN:VEVENT
+UID:recent@example.com
+SUMMARY:이번 회의
+DTSTART:20240710T090000Z
+DTEND:20240710T100000Z
+END:VEVENT
+END:VCALENDAR
+`
+
+// importedCalendar는 가져오기 경로와 같은 방식으로 ICS를 수집 항목으로 바꿉니다.
+func importedCalendar(t *testing.T) []ingestItem {
+	t.Helper()
+	window := importer.Window{
+		Since: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Until: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	var items []ingestItem
+	err := importer.ParseICS(strings.NewReader(consentTestCalendar), consentTestUser, window, importer.ICSOptions{}, func(evt importer.Event) error {
+		items = append(items, importItem(consentTestUser, evt))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ParseICS: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("got %d imported items, want 2", len(items))
+	}
+	return items
+}
+
+func TestConsentCheckImport(t *testing.T) {
+	registry, err := validation.NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	grantedAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	grant := func(scope string) consentSet {
+		return consentSet{consentTestUser: {
+			validation.KindCalendar: {UserID: consentTestUser, Source: validation.KindCalendar, Scope: scope, GrantedAt: grantedAt},
+		}}
+	}
+
+	tests := []struct {
+		name     string
+		consents consentSet
+		// accepted는 가져온 일정(과거, 최근)별 수락 여부입니다.
+		accepted [2]bool
+	}{
+		{name: "collect 범위는 동의 이전 일정을 거절", consents: grant(consentScopeCollect), accepted: [2]bool{false, true}},
+		{name: "history 범위는 과거 일정도 수락", consents: grant(consentScopeHistory), accepted: [2]bool{true, true}},
+		{name: "동의가 없으면 모두 거절", consents: consentSet{}, accepted: [2]bool{false, false}},
+		{name: "다른 종류의 동의로는 거절", consents: consentSet{consentTestUser: {
+			validation.KindLocation: {Scope: consentScopeHistory, GrantedAt: grantedAt},
+		}}, accepted: [2]bool{false, false}},
+		{name: "동의 검사가 꺼져 있으면 모두 수락", consents: nil, accepted: [2]bool{true, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, item := range importedCalendar(t) {
+				reason := tt.consents.check(registry, item.Payload)
+				if got := reason == ""; got != tt.accepted[i] {
+					t.Errorf("item %d (%s): accepted = %v, want %v (reason %q)",
+						i, item.Payload.StartedAt.Format(time.RFC3339), got, tt.accepted[i], reason)
+				}
+			}
+		})
+	}
+}
+
+func TestConsentCheckReasonMentionsHistoryScope(t *testing.T) {
+	registry, err := validation.NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	consents := consentSet{consentTestUser: {
+		validation.KindCalendar: repository.Consent{Scope: consentScopeCollect, GrantedAt: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+	}}
+	reason := consents.check(registry, importedCalendar(t)[0].Payload)
+	if !strings.Contains(reason, consentScopeHistory) {
+		t.Errorf("reason = %q, want a hint about the %q scope", reason, consentScopeHistory)
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"daylog/services/ingestion/repository"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// deviceTokenPrefix는 기기 토큰을 로그나 설정 파일에서 식별하기 쉽도록 붙이는 접두어입니다.
+const deviceTokenPrefix = "dlg_dev_"
+
+// 등록 가능한 기기 플랫폼
+var devicePlatforms = map[string]bool{
+	"ios":     true,
+	"android": true,
+	"watchos": true,
+	"wearos":  true,
+	"web":     true,
+	"other":   true,
+}
+
+var errDeviceUserMismatch = errors.New("user_id does not match device owner")
+
+// userIDHeader는 게이트웨이가 인증한 사용자 ID를 내부 관리 API에 전달하는 헤더입니다.
+const userIDHeader = "X-User-Id"
+
+type deviceContextKey struct{}
+
+type registerDeviceRequest struct {
+	Platform string `json:"platform"`
+	Name     string `json:"name"`
+}
+
+// registerDeviceResponse는 등록된 기기와 원문 토큰입니다. 토큰은 이 응답에서만 노출됩니다.
+type registerDeviceResponse struct {
+	repository.Device
+	Token string `json:"token"`
+}
+
+// handleRegisterDevice는 사용자의 기기를 등록하고 기기 전용 수집 토큰을 발급합니다.
+func (s *server) handleRegisterDevice(w http.ResponseWriter, r *http.Request) {
+	if s.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "devices require postgres"})
+		return
+	}
+
+	userID := mux.Vars(r)["userId"]
+	if _, err := uuid.Parse(userID); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "userId must be a UUID"})
+		return
+	}
+
+	var req registerDeviceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+	req.Platform = strings.ToLower(strings.TrimSpace(req.Platform))
+	if !devicePlatforms[req.Platform] {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "platform must be one of ios, android, watchos, wearos, web, other"})
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if len(req.Name) > 100 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "name must be at most 100 characters"})
+		return
+	}
+
+	token, err := newDeviceToken()
+	if err != nil {
+		s.logger.Errorw("failed to generate device token", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to register device"})
+		return
+	}
+
+	device, err := s.repo.RegisterDevice(r.Context(), repository.Device{
+		DeviceID: uuid.NewString(),
+		UserID:   userID,
+		Platform: req.Platform,
+		Name:     req.Name,
+	}, hashDeviceToken(token))
+	if err != nil {
+		s.logger.Errorw("failed to register device", "error", err, "user_id", userID)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to register device"})
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, registerDeviceResponse{Device: device, Token: token})
+}
+
+// handleListDevices는 사용자의 연동 기기 목록을 반환합니다. include_revoked=true이면 폐기된 기기도 포함합니다.
+func (s *server) handleListDevices(w http.ResponseWriter, r *http.Request) {
+	if s.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "devices require postgres"})
+		return
+	}
+
+	userID := mux.Vars(r)["userId"]
+	if _, err := uuid.Parse(userID); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "userId must be a UUID"})
+		return
+	}
+	includeRevoked := r.URL.Query().Get("include_revoked") == "true"
+
+	devices, err := s.repo.ListDevices(r.Context(), userID, includeRevoked)
+	if err != nil {
+		s.logger.Errorw("failed to list devices", "error", err, "user_id", userID)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to list devices"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, devices)
+}
+
+// handleRevokeDevice는 기기를 폐기합니다. 폐기된 기기의 토큰은 즉시 인증에 실패합니다.
+func (s *server) handleRevokeDevice(w http.ResponseWriter, r *http.Request) {
+	if s.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "devices require postgres"})
+		return
+	}
+
+	vars := mux.Vars(r)
+	userID, deviceID := vars["userId"], vars["deviceId"]
+	if _, err := uuid.Parse(userID); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "userId must be a UUID"})
+		return
+	}
+	if _, err := uuid.Parse(deviceID); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "deviceId must be a UUID"})
+		return
+	}
+
+	revoked, err := s.repo.RevokeDevice(r.Context(), userID, deviceID)
+	if err != nil {
+		s.logger.Errorw("failed to revoke device", "error", err, "device_id", deviceID)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to revoke device"})
+		return
+	}
+	if !revoked {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "device not found"})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// requireDevice는 Authorization: Bearer 기기 토큰을 검증하고 인증된 기기를 요청 컨텍스트에 담습니다.
+// 인증에 성공하면 기기의 last_seen이 갱신됩니다.
+func (s *server) requireDevice(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.cfg.Ingestion.RequireDeviceAuth || s.repo == nil {
+			next(w, r)
+			return
+		}
+
+		token, ok := bearerToken(r.Header.Get("Authorization"))
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="daylog-ingestion"`)
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "device token required"})
+			return
+		}
+
+		device, err := s.repo.AuthenticateDevice(r.Context(), hashDeviceToken(token))
+		if err != nil {
+			s.logger.Errorw("failed to authenticate device", "error", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to authenticate device"})
+			return
+		}
+		if device == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="daylog-ingestion", error="invalid_token"`)
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid or revoked device token"})
+			return
+		}
+
+		next(w, r.WithContext(context.WithValue(r.Context(), deviceContextKey{}, device)))
+	}
+}
+
+// requireUser는 내부 관리 API에서 게이트웨이가 전달한 X-User-Id가 경로의 userId와 같은지 확인합니다.
+// 내부 리스너는 게이트웨이만 접근할 수 있으므로 헤더를 신뢰하며, 사용자는 자신의 자원만 관리할 수 있다.
+func requireUser(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		callerID := strings.TrimSpace(r.Header.Get(userIDHeader))
+		if callerID == "" {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "user authentication required"})
+			return
+		}
+		if callerID != mux.Vars(r)["userId"] {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "userId does not match authenticated user"})
+			return
+		}
+		next(w, r)
+	}
+}
+
+// deviceOwns는 인증된 기기가 userID의 기기인지 확인합니다. 기기 인증이 꺼져 있으면 true입니다.
+func deviceOwns(ctx context.Context, userID string) bool {
+	device, ok := ctx.Value(deviceContextKey{}).(*repository.Device)
+	return !ok || device.UserID == userID
+}
+
+// bindDevice는 인증된 기기의 device_id를 이벤트에 기록하고 user_id를 기기 소유자로 고정합니다.
+// 클라이언트가 보낸 device_id는 신뢰하지 않으며, 기기 인증이 꺼져 있으면 비워 둡니다.
+func bindDevice(ctx context.Context, payload *activityEvent) error {
+	payload.DeviceID = ""
+
+	device, ok := ctx.Value(deviceContextKey{}).(*repository.Device)
+	if !ok {
+		return nil
+	}
+	if payload.UserID == "" {
+		payload.UserID = device.UserID
+	}
+	if payload.UserID != device.UserID {
+		return errDeviceUserMismatch
+	}
+	payload.DeviceID = device.DeviceID
+	return nil
+}
+
+// bearerToken은 Authorization 헤더(또는 gRPC authorization 메타데이터) 값에서 Bearer 토큰을 꺼냅니다.
+func bearerToken(authorization string) (string, bool) {
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// newDeviceToken은 256비트 무작위 기기 토큰을 생성합니다.
+func newDeviceToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return deviceTokenPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashDeviceToken은 저장·조회용 토큰 해시를 반환합니다.
+// 토큰 자체가 고엔트로피 난수이므로 솔트 없는 SHA-256으로 충분하고, 해시로 바로 인덱스 조회할 수 있습니다.
+func hashDeviceToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
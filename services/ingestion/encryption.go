@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"daylog/services/common/config"
+	"daylog/services/common/fieldcrypt"
+	"daylog/services/ingestion/validation"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// fieldEncryption은 저장·발행 전에 소스 종류별로 지정된 메타데이터 경로를 암호화합니다.
+type fieldEncryption struct {
+	enc   *fieldcrypt.Encryptor
+	paths map[string][]string // 소스 종류 → 메타데이터 경로
+}
+
+// newFieldEncryption은 마스터 키 파일을 읽고 `종류:경로` 설정을 해석합니다.
+func newFieldEncryption(pool *pgxpool.Pool, cfg config.EncryptionConfig, registry *validation.Registry) (*fieldEncryption, error) {
+	paths := make(map[string][]string)
+	for _, field := range cfg.Fields {
+		kind, path, ok := strings.Cut(strings.TrimSpace(field), ":")
+		if !ok || path == "" {
+			return nil, fmt.Errorf("encrypted field %q must be kind:path", field)
+		}
+		if !registry.IsKind(kind) {
+			return nil, fmt.Errorf("encrypted field %q: unknown source kind %q", field, kind)
+		}
+		paths[kind] = append(paths[kind], path)
+	}
+
+	masterKey, err := fieldcrypt.LoadMasterKey(cfg.MasterKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := fieldcrypt.NewKeyStore(pool, masterKey, cfg.KeyCacheTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fieldEncryption{enc: fieldcrypt.New(keys), paths: paths}, nil
+}
+
+// encryptMetadata는 이벤트 소스 종류에 지정된 필드를 암호화한 메타데이터를 반환합니다.
+// 멱등 비교용 해시는 평문 기준으로 계산되어야 하므로 hashPayload 이후에 호출합니다.
+func (s *server) encryptMetadata(ctx context.Context, payload activityEvent) (map[string]interface{}, error) {
+	if s.encryption == nil {
+		return payload.Metadata, nil
+	}
+	kind, _ := s.validator.Kind(payload.Source)
+	metadata, err := s.encryption.enc.EncryptFields(ctx, payload.UserID, payload.Metadata, s.encryption.paths[kind])
+	if err != nil {
+		return nil, fmt.Errorf("encrypt metadata: %w", err)
+	}
+	return metadata, nil
+}
+
+// handleShredDataKey는 사용자의 데이터 키를 파기해 암호화된 기존 필드를 복구 불가능하게 만듭니다(crypto-shredding).
+// 되돌릴 수 없으므로 내부 리스너에서 계정 소유자(requireUser)만 호출할 수 있다.
+func (s *server) handleShredDataKey(w http.ResponseWriter, r *http.Request) {
+	if s.encryption == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "field encryption disabled"})
+		return
+	}
+
+	userID := mux.Vars(r)["userId"]
+	if _, err := uuid.Parse(userID); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "userId must be a UUID"})
+		return
+	}
+
+	shredded, err := s.encryption.enc.Shred(r.Context(), userID)
+	if err != nil {
+		s.logger.Errorw("failed to shred data key", "error", err, "user_id", userID)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to shred data key"})
+		return
+	}
+	if !shredded {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "data key not found"})
+		return
+	}
+
+	s.logger.Infow("user data key shredded", "user_id", userID)
+	w.WriteHeader(http.StatusNoContent)
+}
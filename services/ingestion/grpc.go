@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"time"
+
+	"daylog/services/ingestion/ingestionpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// streamFlushSize는 IngestStream이 한 번에 저장하는 이벤트 수입니다. 배치 API의 항목 수 제한과 같다.
+const streamFlushSize = maxBatchItems
+
+var ingestStatuses = map[string]ingestionpb.IngestStatus{
+	ingestStatusAccepted:  ingestionpb.IngestStatus_INGEST_STATUS_ACCEPTED,
+	ingestStatusDuplicate: ingestionpb.IngestStatus_INGEST_STATUS_DUPLICATE,
+	ingestStatusRejected:  ingestionpb.IngestStatus_INGEST_STATUS_REJECTED,
+	ingestStatusConflict:  ingestionpb.IngestStatus_INGEST_STATUS_CONFLICT,
+	ingestStatusNoConsent: ingestionpb.IngestStatus_INGEST_STATUS_NO_CONSENT,
+}
+
+// grpcIngestion은 HTTP 수집 API와 같은 파이프라인(s.ingest)을 사용하는 gRPC 수집 서비스입니다.
+type grpcIngestion struct {
+	ingestionpb.UnimplementedIngestionServiceServer
+	s *server
+}
+
+// newGRPCServer는 기기 인증·로깅 인터셉터와 헬스 서비스가 등록된 gRPC 서버를 생성합니다.
+func newGRPCServer(s *server) (*grpc.Server, *health.Server) {
+	g := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(s.unaryLogging, s.unaryDeviceAuth),
+		grpc.ChainStreamInterceptor(s.streamLogging, s.streamDeviceAuth),
+	)
+	ingestionpb.RegisterIngestionServiceServer(g, &grpcIngestion{s: s})
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(ingestionpb.IngestionService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(g, healthServer)
+	return g, healthServer
+}
+
+func (g *grpcIngestion) Ingest(ctx context.Context, req *ingestionpb.IngestRequest) (*ingestionpb.IngestResult, error) {
+	item, rejected := protoItem(ctx, req)
+	if rejected != nil {
+		return rejected, nil
+	}
+
+	results, err := g.s.ingest(ctx, []ingestItem{item})
+	if err != nil {
+		g.s.logger.Errorw("failed to persist activity event", "error", err)
+		return nil, ingestStatusError(err)
+	}
+	return protoResult(0, results[0]), nil
+}
+
+// IngestStream은 스트림으로 들어온 이벤트를 streamFlushSize개씩 저장합니다.
+// 저장 실패로 스트림이 중단되면 그 전에 저장된 묶음은 유지되므로, 클라이언트는 event_id를 지정해 전체를 재전송하면 된다.
+func (g *grpcIngestion) IngestStream(stream ingestionpb.IngestionService_IngestStreamServer) error {
+	ctx := stream.Context()
+	resp := &ingestionpb.IngestStreamResponse{}
+
+	var (
+		pending []ingestItem
+		indexes []int64
+	)
+	flush := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+		results, err := g.s.ingest(ctx, pending)
+		if err != nil {
+			g.s.logger.Errorw("failed to persist activity event stream", "error", err, "count", len(pending))
+			return ingestStatusError(err)
+		}
+		for j, res := range results {
+			appendStreamResult(resp, protoResult(indexes[j], res))
+		}
+		pending, indexes = pending[:0], indexes[:0]
+		return nil
+	}
+
+	for index := int64(0); ; index++ {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		item, rejected := protoItem(ctx, req)
+		if rejected != nil {
+			rejected.Index = index
+			appendStreamResult(resp, rejected)
+			continue
+		}
+		pending = append(pending, item)
+		indexes = append(indexes, index)
+		if len(pending) >= streamFlushSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	// 기기 불일치로 바로 거절된 항목은 저장된 묶음보다 먼저 기록될 수 있으므로 입력 순서로 정렬한다.
+	sort.Slice(resp.Results, func(i, j int) bool { return resp.Results[i].Index < resp.Results[j].Index })
+	return stream.SendAndClose(resp)
+}
+
+// protoItem은 요청을 수집 항목으로 변환합니다. 기기 소유자와 user_id가 다르면 거절 결과를 반환합니다.
+func protoItem(ctx context.Context, req *ingestionpb.IngestRequest) (ingestItem, *ingestionpb.IngestResult) {
+	evt := req.GetEvent()
+	payload := activityEvent{
+		EventID: evt.GetEventId(),
+		UserID:  evt.GetUserId(),
+		Source:  evt.GetSource(),
+	}
+	if evt.GetStartedAt() != nil {
+		payload.StartedAt = evt.GetStartedAt().AsTime()
+	}
+	if evt.GetEndedAt() != nil {
+		payload.EndedAt = evt.GetEndedAt().AsTime()
+	}
+	if evt.GetMetadata() != nil {
+		payload.Metadata = evt.GetMetadata().AsMap()
+	}
+
+	if err := bindDevice(ctx, &payload); err != nil {
+		return ingestItem{}, &ingestionpb.IngestResult{
+			Status: ingestionpb.IngestStatus_INGEST_STATUS_REJECTED,
+			Reason: err.Error(),
+		}
+	}
+
+	key := req.GetIdempotencyKey()
+	if key == "" {
+		key = payload.EventID
+	}
+	return ingestItem{Payload: payload, Key: key}, nil
+}
+
+func protoResult(index int64, res ingestResult) *ingestionpb.IngestResult {
+	out := &ingestionpb.IngestResult{
+		Index:   index,
+		EventId: res.EventID,
+		Status:  ingestStatuses[res.Status],
+		Reason:  res.Reason,
+	}
+	for _, f := range res.Fields {
+		out.Fields = append(out.Fields, &ingestionpb.FieldError{Field: f.Field, Message: f.Message})
+	}
+	return out
+}
+
+func appendStreamResult(resp *ingestionpb.IngestStreamResponse, res *ingestionpb.IngestResult) {
+	resp.Results = append(resp.Results, res)
+	switch res.Status {
+	case ingestionpb.IngestStatus_INGEST_STATUS_ACCEPTED:
+		resp.Accepted++
+	case ingestionpb.IngestStatus_INGEST_STATUS_DUPLICATE:
+		resp.Duplicate++
+	default:
+		resp.Rejected++
+	}
+}
+
+// ingestStatusError는 writeIngestError와 같은 기준으로 저장·발행 실패를 gRPC 상태로 변환합니다.
+func ingestStatusError(err error) error {
+	switch {
+	case errors.Is(err, errPublishUnavailable):
+		return status.Error(codes.Unavailable, "event broker unavailable")
+	}
+	return status.Error(codes.Internal, "failed to persist event")
+}
+
+// authenticateRPC는 requireDevice와 같은 규칙으로 authorization 메타데이터의 기기 토큰을 검증하고,
+// 인증된 기기를 담은 컨텍스트를 반환합니다.
+func (s *server) authenticateRPC(ctx context.Context, fullMethod string) (context.Context, error) {
+	if !s.cfg.Ingestion.RequireDeviceAuth || s.repo == nil {
+		return ctx, nil
+	}
+	if fullMethod == healthpb.Health_Check_FullMethodName || fullMethod == healthpb.Health_Watch_FullMethodName {
+		return ctx, nil
+	}
+
+	var authorization string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			authorization = values[0]
+		}
+	}
+	token, ok := bearerToken(authorization)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "device token required")
+	}
+
+	device, err := s.repo.AuthenticateDevice(ctx, hashDeviceToken(token))
+	if err != nil {
+		s.logger.Errorw("failed to authenticate device", "error", err)
+		return nil, status.Error(codes.Internal, "failed to authenticate device")
+	}
+	if device == nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid or revoked device token")
+	}
+	return context.WithValue(ctx, deviceContextKey{}, device), nil
+}
+
+func (s *server) unaryDeviceAuth(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.authenticateRPC(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *server) streamDeviceAuth(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticateRPC(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+func (s *server) unaryLogging(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	s.logRPC(info.FullMethod, start, err)
+	return resp, err
+}
+
+func (s *server) streamLogging(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	s.logRPC(info.FullMethod, start, err)
+	return err
+}
+
+func (s *server) logRPC(method string, start time.Time, err error) {
+	s.logger.Infow("rpc processed",
+		"method", method,
+		"code", status.Code(err).String(),
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+}
+
+// contextStream은 인증된 기기를 담은 컨텍스트로 Context()를 바꾼 ServerStream입니다.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (c *contextStream) Context() context.Context {
+	return c.ctx
+}
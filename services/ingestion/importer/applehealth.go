@@ -0,0 +1,278 @@
+package importer
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SourceAppleHealth는 Apple Health 내보내기에서 가져온 이벤트의 source 값입니다.
+const SourceAppleHealth = "apple_health"
+
+const (
+	appleDateLayout = "2006-01-02 15:04:05 -0700"
+
+	appleWorkoutPrefix = "HKWorkoutActivityType"
+	appleSleepType     = "HKCategoryTypeIdentifierSleepAnalysis"
+	appleSleepPrefix   = "HKCategoryValueSleepAnalysis"
+	appleStepType      = "HKQuantityTypeIdentifierStepCount"
+
+	// stepSessionGap보다 떨어진 걸음 수 레코드는 별도 세션으로 나눈다.
+	stepSessionGap = 10 * time.Minute
+)
+
+// ParseAppleHealthFile은 export.xml 또는 Apple Health 내보내기 ZIP(export.zip)을 파싱합니다.
+func ParseAppleHealthFile(filePath, userID string, window Window, emit EmitFunc) error {
+	zipped, err := isZip(filePath)
+	if err != nil {
+		return fmt.Errorf("inspect upload: %w", err)
+	}
+
+	if !zipped {
+		f, err := openFile(filePath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return ParseAppleHealth(f, userID, window, emit)
+	}
+
+	matched, err := walkZip(filePath, func(name string) bool {
+		return path.Base(name) == "export.xml"
+	}, func(_ string, r io.Reader) error {
+		return ParseAppleHealth(r, userID, window, emit)
+	})
+	if err != nil {
+		return err
+	}
+	if matched == 0 {
+		return errors.New("export.xml not found in archive")
+	}
+	return nil
+}
+
+// ParseAppleHealth는 Apple Health export.xml을 토큰 단위로 스트리밍 파싱합니다.
+// 수백 MB 파일도 메모리에 올리지 않도록 Workout, 수면 분석, 걸음 수 레코드만 골라 처리합니다.
+// 걸음 수 레코드는 기기별로 stepSessionGap 이내에 이어지는 구간을 하나의 세션으로 합칩니다.
+func ParseAppleHealth(r io.Reader, userID string, window Window, emit EmitFunc) error {
+	decoder := xml.NewDecoder(r)
+	// 일부 기기 이름 등에 잘못 이스케이프된 문자가 섞여 있는 경우가 있어 관대하게 파싱한다.
+	decoder.Strict = false
+
+	steps := map[string]*stepSession{}
+
+	for {
+		tok, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("decode export.xml: %w", err)
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		attrs := attrMap(start.Attr)
+		switch start.Name.Local {
+		case "Workout":
+			evt, ok := appleWorkout(userID, attrs)
+			if ok && window.contains(evt.StartedAt) {
+				if err := emit(evt); err != nil {
+					return err
+				}
+			}
+		case "Record":
+			switch attrs["type"] {
+			case appleSleepType:
+				evt, ok := appleSleep(userID, attrs)
+				if ok && window.contains(evt.StartedAt) {
+					if err := emit(evt); err != nil {
+						return err
+					}
+				}
+			case appleStepType:
+				if err := appleSteps(userID, attrs, window, steps, emit); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for device, session := range steps {
+		if err := session.flush(userID, device, window, emit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appleWorkout(userID string, attrs map[string]string) (Event, bool) {
+	startedAt, endedAt, ok := appleInterval(attrs)
+	if !ok {
+		return Event{}, false
+	}
+
+	activity := strings.TrimPrefix(attrs["workoutActivityType"], appleWorkoutPrefix)
+	if activity == "" {
+		activity = "Other"
+	}
+
+	metadata := map[string]any{
+		"type":         healthTypeWorkout,
+		"workout_type": snakeCase(activity),
+	}
+	if device := attrs["sourceName"]; device != "" {
+		metadata["device_name"] = device
+	}
+	if v, err := strconv.ParseFloat(attrs["totalDistance"], 64); err == nil {
+		if meters, ok := toMeters(v, attrs["totalDistanceUnit"]); ok {
+			metadata["distance_m"] = meters
+		}
+	}
+	if v, err := strconv.ParseFloat(attrs["totalEnergyBurned"], 64); err == nil {
+		if kcal, ok := toKcal(v, attrs["totalEnergyBurnedUnit"]); ok {
+			metadata["energy_kcal"] = kcal
+		}
+	}
+
+	return Event{
+		EventID:   StableEventID(userID, SourceAppleHealth, "workout", attrs["workoutActivityType"], attrs["startDate"], attrs["endDate"], attrs["sourceName"]),
+		Source:    SourceAppleHealth,
+		StartedAt: startedAt,
+		EndedAt:   endedAt,
+		Metadata:  metadata,
+	}, true
+}
+
+func appleSleep(userID string, attrs map[string]string) (Event, bool) {
+	startedAt, endedAt, ok := appleInterval(attrs)
+	if !ok {
+		return Event{}, false
+	}
+
+	metadata := map[string]any{
+		"type":        healthTypeSleep,
+		"sleep_stage": snakeCase(strings.TrimPrefix(attrs["value"], appleSleepPrefix)),
+	}
+	if device := attrs["sourceName"]; device != "" {
+		metadata["device_name"] = device
+	}
+
+	return Event{
+		EventID:   StableEventID(userID, SourceAppleHealth, "sleep", attrs["value"], attrs["startDate"], attrs["endDate"], attrs["sourceName"]),
+		Source:    SourceAppleHealth,
+		StartedAt: startedAt,
+		EndedAt:   endedAt,
+		Metadata:  metadata,
+	}, true
+}
+
+// stepSession은 연속된 걸음 수 레코드를 누적한 세션입니다.
+type stepSession struct {
+	startedAt time.Time
+	endedAt   time.Time
+	count     int64
+}
+
+func (s *stepSession) flush(userID, device string, window Window, emit EmitFunc) error {
+	if s == nil || s.count == 0 || !window.contains(s.startedAt) {
+		return nil
+	}
+
+	metadata := map[string]any{
+		"type":       healthTypeSteps,
+		"step_count": s.count,
+	}
+	if device != "" {
+		metadata["device_name"] = device
+	}
+
+	return emit(Event{
+		EventID:   StableEventID(userID, SourceAppleHealth, "steps", device, s.startedAt.UTC().Format(time.RFC3339)),
+		Source:    SourceAppleHealth,
+		StartedAt: s.startedAt,
+		EndedAt:   s.endedAt,
+		Metadata:  metadata,
+	})
+}
+
+func appleSteps(userID string, attrs map[string]string, window Window, sessions map[string]*stepSession, emit EmitFunc) error {
+	startedAt, endedAt, ok := appleInterval(attrs)
+	if !ok {
+		return nil
+	}
+	count, err := strconv.ParseFloat(attrs["value"], 64)
+	if err != nil || count <= 0 {
+		return nil
+	}
+
+	device := attrs["sourceName"]
+	session := sessions[device]
+	if session != nil && startedAt.Sub(session.endedAt) <= stepSessionGap && !startedAt.Before(session.startedAt) {
+		if endedAt.After(session.endedAt) {
+			session.endedAt = endedAt
+		}
+		session.count += int64(count)
+		return nil
+	}
+
+	if err := session.flush(userID, device, window, emit); err != nil {
+		return err
+	}
+	sessions[device] = &stepSession{startedAt: startedAt, endedAt: endedAt, count: int64(count)}
+	return nil
+}
+
+func appleInterval(attrs map[string]string) (time.Time, time.Time, bool) {
+	startedAt, err := time.Parse(appleDateLayout, attrs["startDate"])
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	endedAt, err := time.Parse(appleDateLayout, attrs["endDate"])
+	if err != nil || endedAt.Before(startedAt) {
+		return time.Time{}, time.Time{}, false
+	}
+	return startedAt, endedAt, true
+}
+
+func attrMap(attrs []xml.Attr) map[string]string {
+	m := make(map[string]string, len(attrs))
+	for _, a := range attrs {
+		m[a.Name.Local] = a.Value
+	}
+	return m
+}
+
+func toMeters(v float64, unit string) (float64, bool) {
+	switch unit {
+	case "m":
+		return v, true
+	case "km":
+		return v * 1000, true
+	case "mi":
+		return v * 1609.344, true
+	case "yd":
+		return v * 0.9144, true
+	case "ft":
+		return v * 0.3048, true
+	}
+	return 0, false
+}
+
+func toKcal(v float64, unit string) (float64, bool) {
+	switch unit {
+	case "kcal", "Cal":
+		return v, true
+	case "kJ":
+		return v / 4.184, true
+	}
+	return 0, false
+}
@@ -0,0 +1,159 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// SourceGoogleFit은 Google Fit(Takeout)에서 가져온 이벤트의 source 값입니다.
+const SourceGoogleFit = "google_fit"
+
+// googleFitSession은 Takeout `Fit/All Sessions/*.json` 파일 하나의 형식입니다.
+type googleFitSession struct {
+	Name            string               `json:"name"`
+	FitnessActivity string               `json:"fitnessActivity"`
+	StartTime       time.Time            `json:"startTime"`
+	EndTime         time.Time            `json:"endTime"`
+	Aggregate       []googleFitAggregate `json:"aggregate"`
+}
+
+type googleFitAggregate struct {
+	MetricName string   `json:"metricName"`
+	IntValue   *int64   `json:"intValue"`
+	FloatValue *float64 `json:"floatValue"`
+}
+
+func (a googleFitAggregate) value() (float64, bool) {
+	switch {
+	case a.FloatValue != nil:
+		return *a.FloatValue, true
+	case a.IntValue != nil:
+		return float64(*a.IntValue), true
+	}
+	return 0, false
+}
+
+// ParseGoogleFitFile은 Google Takeout ZIP 또는 세션 JSON(단일 객체나 배열)을 파싱합니다.
+// ZIP에서는 `Fit/All Sessions/` 아래의 JSON 파일만 읽습니다.
+func ParseGoogleFitFile(filePath, userID string, window Window, emit EmitFunc) error {
+	zipped, err := isZip(filePath)
+	if err != nil {
+		return fmt.Errorf("inspect upload: %w", err)
+	}
+
+	if !zipped {
+		f, err := openFile(filePath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return ParseGoogleFit(f, userID, window, emit)
+	}
+
+	matched, err := walkZip(filePath, func(name string) bool {
+		lower := strings.ToLower(name)
+		return strings.Contains(lower, "fit/all sessions/") && strings.HasSuffix(lower, ".json")
+	}, func(name string, r io.Reader) error {
+		if err := ParseGoogleFit(r, userID, window, emit); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if matched == 0 {
+		return errors.New("no Fit/All Sessions JSON files found in archive")
+	}
+	return nil
+}
+
+// ParseGoogleFit은 Google Fit 세션 JSON을 읽어 운동·수면·걸음 세션 이벤트로 변환합니다.
+func ParseGoogleFit(r io.Reader, userID string, window Window, emit EmitFunc) error {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("read session json: %w", err)
+	}
+
+	var sessions []googleFitSession
+	trimmed := strings.TrimSpace(string(raw))
+	if strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal(raw, &sessions); err != nil {
+			return fmt.Errorf("decode session json: %w", err)
+		}
+	} else {
+		var session googleFitSession
+		if err := json.Unmarshal(raw, &session); err != nil {
+			return fmt.Errorf("decode session json: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	for _, session := range sessions {
+		evt, ok := googleFitEvent(userID, session)
+		if !ok || !window.contains(evt.StartedAt) {
+			continue
+		}
+		if err := emit(evt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func googleFitEvent(userID string, session googleFitSession) (Event, bool) {
+	if session.StartTime.IsZero() || session.EndTime.IsZero() || session.EndTime.Before(session.StartTime) {
+		return Event{}, false
+	}
+
+	activity := strings.ToLower(session.FitnessActivity)
+	metadata := map[string]any{}
+
+	var steps float64
+	for _, agg := range session.Aggregate {
+		v, ok := agg.value()
+		if !ok {
+			continue
+		}
+		switch agg.MetricName {
+		case "com.google.step_count.delta":
+			steps = v
+			metadata["step_count"] = int64(v)
+		case "com.google.distance.delta":
+			metadata["distance_m"] = v
+		case "com.google.calories.expended":
+			metadata["energy_kcal"] = v
+		}
+	}
+
+	switch {
+	case activity == "sleep" || strings.HasPrefix(activity, "sleep."):
+		metadata["type"] = healthTypeSleep
+		if stage := strings.TrimPrefix(activity, "sleep."); stage != activity {
+			metadata["sleep_stage"] = stage
+		}
+	case activity == "walking" && steps > 0:
+		metadata["type"] = healthTypeSteps
+	default:
+		if activity == "" {
+			activity = "other"
+		}
+		metadata["type"] = healthTypeWorkout
+		metadata["workout_type"] = strings.ReplaceAll(activity, ".", "_")
+	}
+	if session.Name != "" {
+		metadata["title"] = session.Name
+	}
+
+	return Event{
+		EventID:   StableEventID(userID, SourceGoogleFit, activity, session.StartTime.UTC().Format(time.RFC3339Nano), session.EndTime.UTC().Format(time.RFC3339Nano)),
+		Source:    SourceGoogleFit,
+		StartedAt: session.StartTime,
+		EndedAt:   session.EndTime,
+		Metadata:  metadata,
+	}, true
+}
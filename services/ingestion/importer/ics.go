@@ -0,0 +1,508 @@
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+// SourceCalendar는 캘린더(ICS)에서 가져온 이벤트의 source 값입니다.
+const SourceCalendar = "calendar"
+
+// maxCalendarInstances는 하나의 ICS 파일에서 전개할 최대 일정 수입니다.
+const maxCalendarInstances = 10000
+
+// maxCalendarScan은 반복 규칙 하나에서 window에 닿기까지 건너뛸 수 있는 최대 회차 수입니다.
+const maxCalendarScan = 1000000
+
+// ErrTooManyInstances는 반복 일정 전개 결과가 maxCalendarInstances를 넘을 때 반환됩니다.
+var ErrTooManyInstances = fmt.Errorf("calendar expands to more than %d instances", maxCalendarInstances)
+
+// ErrRecurrenceTooDense는 window 이전 회차가 maxCalendarScan을 넘는 반복 규칙에 대해 반환됩니다.
+var ErrRecurrenceTooDense = fmt.Errorf("calendar recurrence has more than %d instances before window", maxCalendarScan)
+
+// ICSOptions는 ICS 전개 옵션입니다.
+type ICSOptions struct {
+	// IncludeAllDay가 false이면 종일 일정은 건너뛴다.
+	IncludeAllDay bool
+}
+
+// vevent는 파싱된 VEVENT 하나입니다.
+type vevent struct {
+	uid          string
+	summary      string
+	location     string
+	status       string
+	start        time.Time
+	end          time.Time
+	duration     time.Duration
+	hasEnd       bool
+	allDay       bool
+	rrule        string
+	exdates      []time.Time
+	rdates       []time.Time
+	recurrenceID time.Time
+	attendees    int
+}
+
+func (v *vevent) length() time.Duration {
+	switch {
+	case v.hasEnd && v.end.After(v.start):
+		return v.end.Sub(v.start)
+	case v.duration > 0:
+		return v.duration
+	case v.allDay:
+		return 24 * time.Hour
+	}
+	return 0
+}
+
+// icsProperty는 `NAME;PARAM=VALUE:value` 형식의 한 줄입니다.
+type icsProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// ParseICS는 ICS(iCalendar) 문서의 VEVENT를 window 기간으로 전개해 캘린더 이벤트로 변환합니다.
+// RRULE/RDATE/EXDATE 반복 규칙을 전개하고 RECURRENCE-ID로 수정된 회차는 원래 회차를 대체합니다.
+// event_id는 반복 일정이면 UID와 회차 시작 시각(RECURRENCE-ID)으로, 단일 일정이면 UID만으로 만들어
+// 재가져오기 시에도 동일하고 단일 일정의 시간이 바뀌어도 같은 이벤트로 갱신됩니다.
+// 반복 일정을 전개하려면 window의 Since와 Until이 모두 지정되어야 합니다.
+func ParseICS(r io.Reader, userID string, window Window, opts ICSOptions, emit EmitFunc) error {
+	if window.Since.IsZero() || window.Until.IsZero() || !window.Since.Before(window.Until) {
+		return errors.New("calendar window requires since < until")
+	}
+
+	events, calendarName, err := parseVEvents(r)
+	if err != nil {
+		return err
+	}
+
+	masters := map[string]*vevent{}
+	overrides := map[string]map[int64]*vevent{}
+	for _, ev := range events {
+		if ev.recurrenceID.IsZero() {
+			masters[ev.uid] = ev
+			continue
+		}
+		if overrides[ev.uid] == nil {
+			overrides[ev.uid] = map[int64]*vevent{}
+		}
+		overrides[ev.uid][ev.recurrenceID.Unix()] = ev
+	}
+
+	emitted := 0
+	// recurrenceID가 zero이면 반복되지 않는 단일 일정이다.
+	emitInstance := func(ev *vevent, start, recurrenceID time.Time) error {
+		if ev.status == "CANCELLED" || (ev.allDay && !opts.IncludeAllDay) {
+			return nil
+		}
+		end := start.Add(ev.length())
+		// window와 겹치는 회차만 가져온다.
+		overlaps := start.Before(window.Until) && (end.After(window.Since) || !start.Before(window.Since))
+		if !overlaps {
+			return nil
+		}
+		emitted++
+		if emitted > maxCalendarInstances {
+			return ErrTooManyInstances
+		}
+
+		metadata := map[string]any{
+			"uid":            ev.uid,
+			"attendee_count": ev.attendees,
+			"all_day":        ev.allDay,
+		}
+		idParts := []string{SourceCalendar, ev.uid}
+		if !recurrenceID.IsZero() {
+			recurrenceKey := recurrenceID.UTC().Format(time.RFC3339)
+			metadata["recurrence_id"] = recurrenceKey
+			idParts = append(idParts, recurrenceKey)
+		}
+		if ev.summary != "" {
+			metadata["title"] = ev.summary
+		}
+		if ev.location != "" {
+			metadata["location"] = ev.location
+		}
+		if calendarName != "" {
+			metadata["calendar_name"] = calendarName
+		}
+
+		return emit(Event{
+			EventID:   StableEventID(userID, idParts...),
+			Source:    SourceCalendar,
+			StartedAt: start,
+			EndedAt:   end,
+			Metadata:  metadata,
+		})
+	}
+
+	for uid, master := range masters {
+		if !master.recurring() {
+			if err := emitInstance(master, master.start, time.Time{}); err != nil {
+				return err
+			}
+			continue
+		}
+		instances, err := master.occurrences(window)
+		if err != nil {
+			return fmt.Errorf("expand %s: %w", uid, err)
+		}
+		for _, start := range instances {
+			if _, overridden := overrides[uid][start.Unix()]; overridden {
+				continue
+			}
+			if err := emitInstance(master, start, start); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, byRecurrence := range overrides {
+		for _, ev := range byRecurrence {
+			if err := emitInstance(ev, ev.start, ev.recurrenceID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// recurring은 RRULE이나 RDATE로 반복되는 일정인지 반환합니다.
+func (v *vevent) recurring() bool {
+	return v.rrule != "" || len(v.rdates) > 0
+}
+
+// occurrences는 window와 겹칠 수 있는 회차 시작 시각을 반환합니다.
+// 규칙을 순서대로 따라가며 maxCalendarInstances를 넘는 즉시 멈추므로 전체 회차를 메모리에 만들지 않는다.
+func (v *vevent) occurrences(window Window) ([]time.Time, error) {
+	if !v.recurring() {
+		return []time.Time{v.start}, nil
+	}
+
+	set := &rrule.Set{}
+	if v.rrule != "" {
+		opt, err := rrule.StrToROptionInLocation(v.rrule, v.start.Location())
+		if err != nil {
+			return nil, fmt.Errorf("parse rrule: %w", err)
+		}
+		opt.Dtstart = v.start
+		rule, err := rrule.NewRRule(*opt)
+		if err != nil {
+			return nil, fmt.Errorf("build rrule: %w", err)
+		}
+		set.RRule(rule)
+	} else {
+		// RDATE만 있는 경우 DTSTART 자체도 첫 회차다.
+		set.RDate(v.start)
+	}
+	set.DTStart(v.start)
+	for _, rd := range v.rdates {
+		set.RDate(rd)
+	}
+	for _, ex := range v.exdates {
+		set.ExDate(ex)
+	}
+
+	// 시작이 window 이전이어도 window와 겹치는 회차를 포함하도록 일정 길이만큼 앞당겨 조회한다.
+	since := window.Since.Add(-v.length())
+	var instances []time.Time
+	next := set.Iterator()
+	for skipped := 0; ; {
+		t, ok := next()
+		if !ok || t.After(window.Until) {
+			break
+		}
+		if t.Before(since) {
+			skipped++
+			if skipped > maxCalendarScan {
+				return nil, ErrRecurrenceTooDense
+			}
+			continue
+		}
+		instances = append(instances, t)
+		if len(instances) > maxCalendarInstances {
+			return nil, ErrTooManyInstances
+		}
+	}
+	return instances, nil
+}
+
+// parseVEvents는 ICS 문서에서 VEVENT 목록과 X-WR-CALNAME을 읽습니다.
+func parseVEvents(r io.Reader) ([]*vevent, string, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var (
+		events       []*vevent
+		current      *vevent
+		depth        int // VEVENT 내부의 VALARM 등 하위 컴포넌트 깊이
+		calendarName string
+		defaultLoc   = time.UTC
+	)
+
+	for _, line := range lines {
+		prop, ok := parseProperty(line)
+		if !ok {
+			continue
+		}
+
+		switch prop.name {
+		case "BEGIN":
+			if prop.value == "VEVENT" && current == nil {
+				current = &vevent{}
+				continue
+			}
+			if current != nil {
+				depth++
+			}
+			continue
+		case "END":
+			if current == nil {
+				continue
+			}
+			if depth > 0 {
+				depth--
+				continue
+			}
+			if prop.value == "VEVENT" {
+				if current.uid != "" && !current.start.IsZero() {
+					events = append(events, current)
+				}
+				current = nil
+			}
+			continue
+		}
+
+		if current == nil {
+			switch prop.name {
+			case "X-WR-CALNAME":
+				calendarName = unescapeText(prop.value)
+			case "X-WR-TIMEZONE":
+				if loc, err := time.LoadLocation(prop.value); err == nil {
+					defaultLoc = loc
+				}
+			}
+			continue
+		}
+		if depth > 0 {
+			continue
+		}
+
+		switch prop.name {
+		case "UID":
+			current.uid = prop.value
+		case "SUMMARY":
+			current.summary = unescapeText(prop.value)
+		case "LOCATION":
+			current.location = unescapeText(prop.value)
+		case "STATUS":
+			current.status = strings.ToUpper(prop.value)
+		case "DTSTART":
+			t, allDay, err := parseICSTime(prop, defaultLoc)
+			if err != nil {
+				return nil, "", fmt.Errorf("DTSTART: %w", err)
+			}
+			current.start, current.allDay = t, allDay
+		case "DTEND":
+			t, _, err := parseICSTime(prop, defaultLoc)
+			if err != nil {
+				return nil, "", fmt.Errorf("DTEND: %w", err)
+			}
+			current.end, current.hasEnd = t, true
+		case "DURATION":
+			d, err := parseICSDuration(prop.value)
+			if err != nil {
+				return nil, "", fmt.Errorf("DURATION: %w", err)
+			}
+			current.duration = d
+		case "RRULE":
+			current.rrule = prop.value
+		case "EXDATE", "RDATE":
+			times, err := parseICSTimeList(prop, defaultLoc)
+			if err != nil {
+				return nil, "", fmt.Errorf("%s: %w", prop.name, err)
+			}
+			if prop.name == "EXDATE" {
+				current.exdates = append(current.exdates, times...)
+			} else {
+				current.rdates = append(current.rdates, times...)
+			}
+		case "RECURRENCE-ID":
+			t, _, err := parseICSTime(prop, defaultLoc)
+			if err != nil {
+				return nil, "", fmt.Errorf("RECURRENCE-ID: %w", err)
+			}
+			current.recurrenceID = t
+		case "ATTENDEE":
+			current.attendees++
+		}
+	}
+
+	for _, ev := range events {
+		if ev.hasEnd && ev.end.Before(ev.start) {
+			return nil, "", fmt.Errorf("event %s ends before it starts", ev.uid)
+		}
+	}
+
+	return events, calendarName, nil
+}
+
+// unfoldLines는 RFC 5545 줄 접기(CRLF 뒤 공백/탭)를 풀어 논리적인 줄 목록을 반환합니다.
+func unfoldLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read ics: %w", err)
+	}
+	return lines, nil
+}
+
+// parseProperty는 따옴표로 감싼 파라미터 값 안의 `:`와 `;`를 고려해 속성 한 줄을 나눕니다.
+func parseProperty(line string) (icsProperty, bool) {
+	inQuote := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuote = !inQuote
+		} else if r == ':' && !inQuote {
+			colon = i
+			break
+		}
+	}
+	if colon <= 0 {
+		return icsProperty{}, false
+	}
+
+	head, value := line[:colon], line[colon+1:]
+	parts := splitOutsideQuotes(head, ';')
+	prop := icsProperty{
+		name:   strings.ToUpper(parts[0]),
+		params: map[string]string{},
+		value:  value,
+	}
+	for _, p := range parts[1:] {
+		key, val, ok := strings.Cut(p, "=")
+		if !ok {
+			continue
+		}
+		prop.params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+	}
+	return prop, true
+}
+
+func splitOutsideQuotes(s string, sep rune) []string {
+	var (
+		parts   []string
+		inQuote bool
+		start   int
+	)
+	for i, r := range s {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+		case r == sep && !inQuote:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// parseICSTime은 DATE 또는 DATE-TIME 값을 해석합니다. TZID가 없는 floating 시각은 defaultLoc 기준입니다.
+func parseICSTime(prop icsProperty, defaultLoc *time.Location) (time.Time, bool, error) {
+	loc := defaultLoc
+	if tzid := prop.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	return parseICSValue(strings.TrimSpace(prop.value), prop.params["VALUE"] == "DATE", loc)
+}
+
+func parseICSTimeList(prop icsProperty, defaultLoc *time.Location) ([]time.Time, error) {
+	var times []time.Time
+	for _, raw := range strings.Split(prop.value, ",") {
+		if raw == "" {
+			continue
+		}
+		single := prop
+		single.value = raw
+		t, _, err := parseICSTime(single, defaultLoc)
+		if err != nil {
+			return nil, err
+		}
+		times = append(times, t)
+	}
+	return times, nil
+}
+
+func parseICSValue(value string, isDate bool, loc *time.Location) (time.Time, bool, error) {
+	switch {
+	case isDate || len(value) == len("20060102"):
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	case strings.HasSuffix(value, "Z"):
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	default:
+		t, err := time.ParseInLocation("20060102T150405", value, loc)
+		return t, false, err
+	}
+}
+
+var icsDurationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseICSDuration은 `PT1H30M`, `P1D`, `P2W` 형식의 기간을 해석합니다.
+func parseICSDuration(value string) (time.Duration, error) {
+	m := icsDurationPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if m[i+2] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(m[i+2])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		d += time.Duration(n) * unit
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+func unescapeText(s string) string {
+	replacer := strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+	return replacer.Replace(s)
+}
@@ -0,0 +1,130 @@
+package importer
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// 건강 메타데이터 type 값 (validation/schemas/health.json과 일치해야 함)
+const (
+	healthTypeWorkout = "workout"
+	healthTypeSleep   = "sleep"
+	healthTypeSteps   = "steps"
+)
+
+// eventNamespace는 가져오기 이벤트의 결정적 event_id를 만들 때 쓰는 UUIDv5 네임스페이스입니다.
+var eventNamespace = uuid.MustParse("17d5abf3-391d-4884-93b2-1760395547cf")
+
+// ErrStop은 EmitFunc가 파싱을 중단시키고 싶을 때 반환합니다.
+var ErrStop = errors.New("import stopped")
+
+// Event는 외부 내보내기 파일에서 추출한 활동 이벤트입니다.
+type Event struct {
+	EventID   string
+	Source    string
+	StartedAt time.Time
+	EndedAt   time.Time
+	Metadata  map[string]any
+}
+
+// EmitFunc는 파서가 이벤트를 하나 추출할 때마다 호출됩니다.
+type EmitFunc func(Event) error
+
+// Window는 가져올 이벤트의 시작 시각 범위입니다. 0 값은 제한 없음을 뜻합니다.
+type Window struct {
+	Since time.Time
+	Until time.Time
+}
+
+func (w Window) contains(t time.Time) bool {
+	if !w.Since.IsZero() && t.Before(w.Since) {
+		return false
+	}
+	if !w.Until.IsZero() && !t.Before(w.Until) {
+		return false
+	}
+	return true
+}
+
+// StableEventID는 같은 사용자·같은 원본 레코드가 항상 같은 event_id를 갖도록 UUIDv5를 생성합니다.
+// 재가져오기 시 멱등 키로 동작해 중복 저장을 막습니다.
+func StableEventID(userID string, parts ...string) string {
+	name := userID + "|" + strings.Join(parts, "|")
+	return uuid.NewSHA1(eventNamespace, []byte(name)).String()
+}
+
+// isZip은 파일이 ZIP 아카이브인지 시그니처로 확인합니다.
+func isZip(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	magic, err := bufio.NewReader(f).Peek(4)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	return bytes.Equal(magic, []byte("PK\x03\x04")), nil
+}
+
+// walkZip은 ZIP 안에서 match를 만족하는 파일마다 fn을 호출합니다.
+func walkZip(path string, match func(name string) bool, fn func(name string, r io.Reader) error) (int, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return 0, fmt.Errorf("open zip archive: %w", err)
+	}
+	defer zr.Close()
+
+	matched := 0
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !match(f.Name) {
+			continue
+		}
+		matched++
+		rc, err := f.Open()
+		if err != nil {
+			return matched, fmt.Errorf("open %s: %w", f.Name, err)
+		}
+		err = fn(f.Name, rc)
+		rc.Close()
+		if err != nil {
+			return matched, err
+		}
+	}
+	return matched, nil
+}
+
+// snakeCase는 `TraditionalStrengthTraining` 같은 식별자를 `traditional_strength_training`으로 바꿉니다.
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func openFile(path string) (*os.File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open upload: %w", err)
+	}
+	return f, nil
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"daylog/services/ingestion/importer"
+	"daylog/services/ingestion/repository"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// 가져오기 형식
+const (
+	importFormatAppleHealth = "apple_health"
+	importFormatGoogleFit   = "google_fit"
+)
+
+// importParser는 업로드된 파일을 읽어 이벤트를 하나씩 emit하는 파서입니다.
+type importParser func(path, userID string, window importer.Window, emit importer.EmitFunc) error
+
+var importParsers = map[string]importParser{
+	importFormatAppleHealth: importer.ParseAppleHealthFile,
+	importFormatGoogleFit:   importer.ParseGoogleFitFile,
+}
+
+// handleImport는 내보내기 파일을 임시 파일로 스트리밍 저장한 뒤 백그라운드 가져오기 작업을 시작합니다.
+// 선택적으로 since/until(RFC3339) 쿼리로 가져올 기간을 제한할 수 있습니다.
+func (s *server) handleImport(format string) http.HandlerFunc {
+	parse := importParsers[format]
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.repo == nil {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "imports require postgres"})
+			return
+		}
+
+		userID := mux.Vars(r)["userId"]
+		if _, err := uuid.Parse(userID); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "userId must be a UUID"})
+			return
+		}
+		if !deviceOwns(r.Context(), userID) {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": errDeviceUserMismatch.Error()})
+			return
+		}
+
+		window, err := parseImportWindow(r)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+
+		path, err := s.spoolUpload(w, r)
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": "upload too large"})
+				return
+			}
+			s.logger.Errorw("failed to spool import upload", "error", err)
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "failed to read upload"})
+			return
+		}
+
+		job, err := s.repo.CreateImportJob(r.Context(), uuid.NewString(), userID, format)
+		if err != nil {
+			_ = os.Remove(path)
+			s.logger.Errorw("failed to create import job", "error", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to create import job"})
+			return
+		}
+
+		s.imports.Add(1)
+		go func() {
+			defer s.imports.Done()
+			s.runImport(job, path, window, parse)
+		}()
+
+		writeJSON(w, http.StatusAccepted, map[string]any{
+			"job":        job,
+			"status_url": "/v1/imports/" + job.JobID,
+		})
+	}
+}
+
+func (s *server) handleGetImport(w http.ResponseWriter, r *http.Request) {
+	if s.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "imports require postgres"})
+		return
+	}
+
+	jobID := mux.Vars(r)["jobId"]
+	if _, err := uuid.Parse(jobID); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "jobId must be a UUID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	job, err := s.repo.GetImportJob(ctx, jobID)
+	if err != nil {
+		s.logger.Errorw("failed to fetch import job", "job_id", jobID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch import job"})
+		return
+	}
+	// 다른 사용자의 작업은 존재 여부도 드러내지 않는다.
+	if job == nil || !deviceOwns(r.Context(), job.UserID) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, job)
+}
+
+// spoolUpload는 요청 본문을 메모리에 올리지 않고 임시 파일로 복사합니다.
+func (s *server) spoolUpload(w http.ResponseWriter, r *http.Request) (string, error) {
+	f, err := os.CreateTemp(s.cfg.Ingestion.ImportDir, "daylog-import-*")
+	if err != nil {
+		return "", fmt.Errorf("create temp file: %w", err)
+	}
+
+	body := http.MaxBytesReader(w, r.Body, s.cfg.Ingestion.ImportMaxBytes)
+	defer body.Close()
+
+	if _, err := io.Copy(f, body); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("close temp file: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// runImport는 업로드 파일을 파싱해 ImportBatchSize 단위로 수집 파이프라인에 넣고 진행 상황을 기록합니다.
+// 동시에 실행되는 작업 수는 importSlots로 제한하며, 서비스 종료 시 진행 중인 작업은 failed로 남습니다.
+func (s *server) runImport(job repository.ImportJob, path string, window importer.Window, parse importParser) {
+	defer os.Remove(path)
+
+	logger := s.logger.With("job_id", job.JobID, "format", job.Format)
+	// 작업 상태 기록은 종료 신호와 무관하게 완료되어야 한다.
+	statusCtx := context.WithoutCancel(s.baseCtx)
+
+	select {
+	case s.importSlots <- struct{}{}:
+		defer func() { <-s.importSlots }()
+	case <-s.baseCtx.Done():
+		job.Status = repository.ImportStatusFailed
+		job.Error = "service shutting down"
+		if err := s.repo.UpdateImportJob(statusCtx, job); err != nil {
+			logger.Errorw("failed to update import job", "error", err)
+		}
+		return
+	}
+
+	job.Status = repository.ImportStatusRunning
+	if err := s.repo.UpdateImportJob(statusCtx, job); err != nil {
+		logger.Errorw("failed to update import job", "error", err)
+	}
+
+	batchSize := s.cfg.Ingestion.ImportBatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	batch := make([]ingestItem, 0, batchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		results, err := s.ingest(s.baseCtx, batch)
+		if err != nil {
+			return err
+		}
+		for _, res := range results {
+			job.Processed++
+			switch res.Status {
+			case ingestStatusAccepted:
+				job.Accepted++
+			case ingestStatusDuplicate:
+				job.Duplicate++
+			default:
+				job.Rejected++
+			}
+		}
+		batch = batch[:0]
+		if err := s.repo.UpdateImportJob(statusCtx, job); err != nil {
+			logger.Warnw("failed to update import progress", "error", err)
+		}
+		return nil
+	}
+
+	err := parse(path, job.UserID, window, func(evt importer.Event) error {
+		if err := s.baseCtx.Err(); err != nil {
+			return err
+		}
+		batch = append(batch, importItem(job.UserID, evt))
+		if len(batch) >= batchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+
+	if err != nil {
+		job.Status = repository.ImportStatusFailed
+		job.Error = err.Error()
+		logger.Errorw("import job failed", "error", err, "processed", job.Processed)
+	} else {
+		job.Status = repository.ImportStatusSucceeded
+		logger.Infow("import job finished",
+			"processed", job.Processed,
+			"accepted", job.Accepted,
+			"duplicate", job.Duplicate,
+			"rejected", job.Rejected,
+		)
+	}
+
+	if err := s.repo.UpdateImportJob(statusCtx, job); err != nil {
+		logger.Errorw("failed to update import job", "error", err)
+	}
+}
+
+// importItem은 가져오기 파일에서 추출한 이벤트를 수집 항목으로 바꿉니다. 결정적 event_id를 멱등 키로 사용한다.
+func importItem(userID string, evt importer.Event) ingestItem {
+	return ingestItem{
+		Payload: activityEvent{
+			EventID:   evt.EventID,
+			UserID:    userID,
+			Source:    evt.Source,
+			StartedAt: evt.StartedAt,
+			EndedAt:   evt.EndedAt,
+			Metadata:  evt.Metadata,
+		},
+		Key: evt.EventID,
+	}
+}
+
+func parseImportWindow(r *http.Request) (importer.Window, error) {
+	var window importer.Window
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return importer.Window{}, errors.New("since must be RFC3339")
+		}
+		window.Since = t
+	}
+	if raw := r.URL.Query().Get("until"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return importer.Window{}, errors.New("until must be RFC3339")
+		}
+		window.Until = t
+	}
+	return window, nil
+}
+
+const (
+	maxCalendarBodyBytes = 10 << 20 // 10 MiB
+	defaultCalendarSpan  = 90 * 24 * time.Hour
+	maxCalendarSpan      = 400 * 24 * time.Hour
+)
+
+// handleCalendarImport는 ICS(iCalendar/CalDAV 내보내기) 문서를 받아 요청한 기간의 일정을 캘린더 이벤트로 수집합니다.
+// 기간은 since/until(RFC3339)로 지정하며 기본값은 최근 90일입니다. 종일 일정은 include_all_day=true일 때만 가져옵니다.
+// ICS 파일은 크기가 작으므로 동기적으로 처리하고 배치 수집과 같은 형식으로 결과를 반환합니다.
+func (s *server) handleCalendarImport(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["userId"]
+	if _, err := uuid.Parse(userID); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "userId must be a UUID"})
+		return
+	}
+	if !deviceOwns(r.Context(), userID) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": errDeviceUserMismatch.Error()})
+		return
+	}
+
+	window, err := parseImportWindow(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if window.Until.IsZero() {
+		window.Until = time.Now().UTC()
+	}
+	if window.Since.IsZero() {
+		window.Since = window.Until.Add(-defaultCalendarSpan)
+	}
+	if !window.Since.Before(window.Until) || window.Until.Sub(window.Since) > maxCalendarSpan {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "since must be before until and span at most 400 days"})
+		return
+	}
+
+	opts := importer.ICSOptions{IncludeAllDay: r.URL.Query().Get("include_all_day") == "true"}
+
+	body := http.MaxBytesReader(w, r.Body, maxCalendarBodyBytes)
+	defer body.Close()
+
+	var items []ingestItem
+	err = importer.ParseICS(body, userID, window, opts, func(evt importer.Event) error {
+		items = append(items, importItem(userID, evt))
+		return nil
+	})
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": "calendar too large"})
+			return
+		}
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid calendar: " + err.Error()})
+		return
+	}
+
+	resp := batchResponse{Results: make([]batchItemResult, 0, len(items))}
+	for start := 0; start < len(items); start += maxBatchItems {
+		end := min(start+maxBatchItems, len(items))
+		results, err := s.ingest(r.Context(), items[start:end])
+		if err != nil {
+			s.logger.Errorw("failed to persist calendar events", "error", err, "user_id", userID)
+			writeIngestError(w, err)
+			return
+		}
+		for j, res := range results {
+			resp.add(start+j, res)
+		}
+	}
+
+	writeJSON(w, http.StatusAccepted, resp)
+}
@@ -0,0 +1,4 @@
+// Package ingestionpb는 ingestion.proto에서 생성한 수집 서비스 gRPC 코드입니다.
+package ingestionpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative ingestion.proto
@@ -0,0 +1,163 @@
+// 수집 서비스 gRPC API
+// ActivityEvent는 HTTP API(POST /v1/events)의 activityEvent와 같은 필드를 가지며, 같은 검증·저장·발행 경로를 거친다.
+// device_id는 서버가 인증된 기기 토큰으로 채우므로 메시지에 없다.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.27.1
+// source: ingestion.proto
+
+package ingestionpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	IngestionService_Ingest_FullMethodName       = "/daylog.ingestion.v1.IngestionService/Ingest"
+	IngestionService_IngestStream_FullMethodName = "/daylog.ingestion.v1.IngestionService/IngestStream"
+)
+
+// IngestionServiceClient is the client API for IngestionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type IngestionServiceClient interface {
+	// Ingest는 이벤트 하나를 수집한다. 항목 단위 거절은 오류가 아니라 IngestResult.status로 반환한다.
+	Ingest(ctx context.Context, in *IngestRequest, opts ...grpc.CallOption) (*IngestResult, error)
+	// IngestStream은 연속 업로드용 클라이언트 스트리밍이다.
+	// 서버는 받은 이벤트를 묶음 단위로 저장하며, 스트림이 끝나면 입력 순서대로 항목별 결과를 반환한다.
+	IngestStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[IngestRequest, IngestStreamResponse], error)
+}
+
+type ingestionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIngestionServiceClient(cc grpc.ClientConnInterface) IngestionServiceClient {
+	return &ingestionServiceClient{cc}
+}
+
+func (c *ingestionServiceClient) Ingest(ctx context.Context, in *IngestRequest, opts ...grpc.CallOption) (*IngestResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(IngestResult)
+	err := c.cc.Invoke(ctx, IngestionService_Ingest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingestionServiceClient) IngestStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[IngestRequest, IngestStreamResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &IngestionService_ServiceDesc.Streams[0], IngestionService_IngestStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[IngestRequest, IngestStreamResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IngestionService_IngestStreamClient = grpc.ClientStreamingClient[IngestRequest, IngestStreamResponse]
+
+// IngestionServiceServer is the server API for IngestionService service.
+// All implementations must embed UnimplementedIngestionServiceServer
+// for forward compatibility.
+type IngestionServiceServer interface {
+	// Ingest는 이벤트 하나를 수집한다. 항목 단위 거절은 오류가 아니라 IngestResult.status로 반환한다.
+	Ingest(context.Context, *IngestRequest) (*IngestResult, error)
+	// IngestStream은 연속 업로드용 클라이언트 스트리밍이다.
+	// 서버는 받은 이벤트를 묶음 단위로 저장하며, 스트림이 끝나면 입력 순서대로 항목별 결과를 반환한다.
+	IngestStream(grpc.ClientStreamingServer[IngestRequest, IngestStreamResponse]) error
+	mustEmbedUnimplementedIngestionServiceServer()
+}
+
+// UnimplementedIngestionServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedIngestionServiceServer struct{}
+
+func (UnimplementedIngestionServiceServer) Ingest(context.Context, *IngestRequest) (*IngestResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ingest not implemented")
+}
+func (UnimplementedIngestionServiceServer) IngestStream(grpc.ClientStreamingServer[IngestRequest, IngestStreamResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method IngestStream not implemented")
+}
+func (UnimplementedIngestionServiceServer) mustEmbedUnimplementedIngestionServiceServer() {}
+func (UnimplementedIngestionServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeIngestionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IngestionServiceServer will
+// result in compilation errors.
+type UnsafeIngestionServiceServer interface {
+	mustEmbedUnimplementedIngestionServiceServer()
+}
+
+func RegisterIngestionServiceServer(s grpc.ServiceRegistrar, srv IngestionServiceServer) {
+	// If the following call pancis, it indicates UnimplementedIngestionServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&IngestionService_ServiceDesc, srv)
+}
+
+func _IngestionService_Ingest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IngestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngestionServiceServer).Ingest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IngestionService_Ingest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngestionServiceServer).Ingest(ctx, req.(*IngestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IngestionService_IngestStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(IngestionServiceServer).IngestStream(&grpc.GenericServerStream[IngestRequest, IngestStreamResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IngestionService_IngestStreamServer = grpc.ClientStreamingServer[IngestRequest, IngestStreamResponse]
+
+// IngestionService_ServiceDesc is the grpc.ServiceDesc for IngestionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var IngestionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "daylog.ingestion.v1.IngestionService",
+	HandlerType: (*IngestionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Ingest",
+			Handler:    _IngestionService_Ingest_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "IngestStream",
+			Handler:       _IngestionService_IngestStream_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "ingestion.proto",
+}
@@ -2,39 +2,83 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
+	_ "time/tzdata" // 런타임 이미지(alpine)에 시간대 데이터가 없어도 사용자 시간대를 해석할 수 있도록 내장한다.
 
 	"daylog/services/common/config"
 	"daylog/services/common/db"
 	"daylog/services/common/logging"
 	"daylog/services/common/messaging"
+	"daylog/services/ingestion/outbox"
+	"daylog/services/ingestion/partner"
 	"daylog/services/ingestion/repository"
+	"daylog/services/ingestion/validation"
 
-	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
 )
 
 type server struct {
-	cfg      config.Config
-	logger   *zap.SugaredLogger
-	producer *messaging.Producer
-	repo     *repository.EventRepository
-	router   *mux.Router
+	cfg        config.Config
+	logger     *zap.SugaredLogger
+	producer   *messaging.Producer
+	repo       *repository.EventRepository
+	validator  *validation.Registry
+	partners   *partner.Registry
+	encryption *fieldEncryption
+	router     *mux.Router
+	// internal은 게이트웨이만 접근하는 내부 리스너의 사용자 관리 API 라우터입니다.
+	internal *mux.Router
+
+	// baseCtx는 서비스 종료 시 취소되며 백그라운드 가져오기 작업에 전달된다.
+	baseCtx     context.Context
+	imports     sync.WaitGroup
+	importSlots chan struct{}
 }
 
 type activityEvent struct {
 	EventID   string                 `json:"event_id,omitempty"`
 	UserID    string                 `json:"user_id"`
+	DeviceID  string                 `json:"device_id,omitempty"`
 	Source    string                 `json:"source"`
 	StartedAt time.Time              `json:"started_at"`
 	EndedAt   time.Time              `json:"ended_at"`
 	Metadata  map[string]interface{} `json:"metadata"`
+
+	// startFloating/endFloating은 오프셋 없이 들어와 사용자 시간대로 해석해야 하는 시각임을 나타냅니다.
+	startFloating bool
+	endFloating   bool
+}
+
+// toRepositoryEvent는 저장용 모델로 변환하며, Kafka로 발행될 메시지 본문을 함께 직렬화합니다.
+func (e activityEvent) toRepositoryEvent() (repository.Event, error) {
+	message, err := json.Marshal(e)
+	if err != nil {
+		return repository.Event{}, fmt.Errorf("marshal activity event: %w", err)
+	}
+	return repository.Event{
+		EventID:        e.EventID,
+		UserID:         e.UserID,
+		DeviceID:       e.DeviceID,
+		Source:         e.Source,
+		TimestampStart: e.StartedAt,
+		TimestampEnd:   e.EndedAt,
+		Metadata:       e.Metadata,
+		Message:        message,
+	}, nil
 }
 
 type healthResponse struct {
@@ -57,11 +101,12 @@ func main() {
 	defer stop()
 
 	var (
-		pool *repository.EventRepository
+		pool   *repository.EventRepository
+		pgPool *pgxpool.Pool
 	)
 
 	if cfg.HasPostgres() {
-		pgPool, err := db.NewPool(ctx, cfg.Postgres.URI)
+		pgPool, err = db.NewPool(ctx, cfg.Postgres.URI)
 		if err != nil {
 			logger.Fatalw("failed to create postgres pool", "error", err)
 		}
@@ -69,20 +114,74 @@ func main() {
 		pool = repository.NewEventRepository(pgPool)
 	} else {
 		logger.Warn("POSTGRES_URI not set, raw events will not be persisted")
+		if cfg.Ingestion.RequireDeviceAuth {
+			logger.Warn("device authentication disabled: device tokens cannot be verified without postgres")
+		}
+		if cfg.Ingestion.RequireConsent {
+			logger.Warn("consent enforcement disabled: source consents cannot be checked without postgres")
+		}
+		if cfg.HasEncryption() {
+			logger.Warn("field encryption disabled: data keys cannot be stored without postgres")
+		}
 	}
 
-	var producer *messaging.Producer
+	var producer, purgeProducer *messaging.Producer
 	if cfg.HasKafka() {
 		producer, err = messaging.NewProducer(cfg.Kafka.Brokers, cfg.Kafka.ActivityTopic, logger)
 		if err != nil {
 			logger.Fatalw("failed to initialise kafka producer", "error", err)
 		}
 		defer producer.Close()
+		purgeProducer, err = messaging.NewProducer(cfg.Kafka.Brokers, cfg.Kafka.PurgeTopic, logger)
+		if err != nil {
+			logger.Fatalw("failed to initialise kafka purge producer", "error", err)
+		}
+		defer purgeProducer.Close()
 	} else {
 		logger.Warn("KAFKA_BROKERS not set, events will not be published to Kafka")
 	}
 
-	srv := newServer(cfg, logger, producer, pool)
+	relayDone := make(chan struct{})
+	if pool != nil && producer != nil {
+		relay := outbox.NewRelay(pool, producer, purgeProducer, logger, cfg.Ingestion)
+		go func() {
+			defer close(relayDone)
+			relay.Run(ctx)
+		}()
+	} else {
+		if pool != nil {
+			logger.Warn("outbox relay disabled: events will accumulate in ingestion_outbox until Kafka is configured")
+		}
+		close(relayDone)
+	}
+
+	validator, err := validation.NewRegistry()
+	if err != nil {
+		logger.Fatalw("failed to load event schemas", "error", err)
+	}
+
+	if _, err := loadLocation(cfg.Ingestion.DefaultTimezone); err != nil {
+		logger.Fatalw("invalid default timezone", "timezone", cfg.Ingestion.DefaultTimezone, "error", err)
+	}
+
+	var encryption *fieldEncryption
+	if cfg.HasEncryption() && pgPool != nil {
+		encryption, err = newFieldEncryption(pgPool, cfg.Encryption, validator)
+		if err != nil {
+			logger.Fatalw("failed to initialise field encryption", "error", err)
+		}
+		logger.Infow("field encryption enabled", "fields", cfg.Encryption.Fields)
+	}
+
+	partners, err := partner.NewRegistry(cfg.Ingestion.PartnerSecrets, cfg.Ingestion.PartnerFormats)
+	if err != nil {
+		logger.Fatalw("failed to load partner webhook configuration", "error", err)
+	}
+	if names := partners.Names(); len(names) > 0 {
+		logger.Infow("partner webhooks enabled", "partners", names)
+	}
+
+	srv := newServer(ctx, cfg, logger, producer, pool, validator, partners, encryption)
 
 	httpServer := &http.Server{
 		Addr:              cfg.Addr(),
@@ -90,39 +189,135 @@ func main() {
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
+	var internalServer *http.Server
+	if cfg.Ingestion.InternalPort != "" {
+		internalServer = &http.Server{
+			Addr:              ":" + cfg.Ingestion.InternalPort,
+			Handler:           srv.internal,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		go func() {
+			logger.Infow("ingestion internal server listening", "addr", internalServer.Addr)
+			if err := internalServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				logger.Fatalw("internal http server error", "error", err)
+			}
+		}()
+	} else {
+		logger.Warn("INGESTION_INTERNAL_PORT not set, device, consent, data key and partner link management API disabled")
+	}
+
+	var (
+		grpcServer   *grpc.Server
+		grpcHealth   *health.Server
+		grpcListener net.Listener
+	)
+	if cfg.Ingestion.GRPCPort != "" {
+		grpcListener, err = net.Listen("tcp", ":"+cfg.Ingestion.GRPCPort)
+		if err != nil {
+			logger.Fatalw("failed to listen for grpc", "port", cfg.Ingestion.GRPCPort, "error", err)
+		}
+		grpcServer, grpcHealth = newGRPCServer(srv)
+		go func() {
+			logger.Infow("ingestion grpc server listening", "addr", grpcListener.Addr().String())
+			if err := grpcServer.Serve(grpcListener); err != nil {
+				logger.Errorw("grpc server error", "error", err)
+			}
+		}()
+	}
+
+	shutdownDone := make(chan struct{})
 	go func() {
+		defer close(shutdownDone)
 		<-ctx.Done()
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
+		if grpcServer != nil {
+			grpcHealth.Shutdown()
+			stopGRPC(shutdownCtx, grpcServer)
+		}
 		if err := httpServer.Shutdown(shutdownCtx); err != nil {
 			logger.Errorw("failed to shutdown http server", "error", err)
 		}
+		if internalServer != nil {
+			if err := internalServer.Shutdown(shutdownCtx); err != nil {
+				logger.Errorw("failed to shutdown internal http server", "error", err)
+			}
+		}
 	}()
 
 	logger.Infow("ingestion service listening", "addr", cfg.Addr())
 	if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
 		logger.Fatalw("http server error", "error", err)
 	}
+
+	// producer.Close 전에 진행 중인 요청, 가져오기 작업, 릴레이 배치를 마무리한다.
+	<-shutdownDone
+	srv.imports.Wait()
+	<-relayDone
+}
+
+// stopGRPC는 진행 중인 RPC가 끝나기를 기다리되, ctx가 만료되면 남은 스트림을 강제로 닫습니다.
+func stopGRPC(ctx context.Context, g *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		g.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		g.Stop()
+	}
 }
 
 func newServer(
+	ctx context.Context,
 	cfg config.Config,
 	logger *zap.SugaredLogger,
 	producer *messaging.Producer,
 	repo *repository.EventRepository,
+	validator *validation.Registry,
+	partners *partner.Registry,
+	encryption *fieldEncryption,
 ) *server {
 	s := &server{
-		cfg:      cfg,
-		logger:   logger,
-		producer: producer,
-		repo:     repo,
-		router:   mux.NewRouter(),
+		cfg:        cfg,
+		logger:     logger,
+		producer:   producer,
+		repo:       repo,
+		validator:  validator,
+		partners:   partners,
+		encryption: encryption,
+		router:     mux.NewRouter(),
+		internal:   mux.NewRouter(),
+
+		baseCtx:     ctx,
+		importSlots: make(chan struct{}, max(cfg.Ingestion.ImportConcurrency, 1)),
 	}
 
 	s.router.Use(s.loggingMiddleware)
 	s.router.HandleFunc("/healthz", s.handleHealth).Methods(http.MethodGet)
 	s.router.HandleFunc("/readyz", s.handleReady).Methods(http.MethodGet)
-	s.router.HandleFunc("/v1/events", s.handleEventIngestion).Methods(http.MethodPost)
+	s.router.HandleFunc("/v1/events", s.requireDevice(s.handleEventIngestion)).Methods(http.MethodPost)
+	s.router.HandleFunc("/v1/events:batch", s.requireDevice(s.handleBatchIngestion)).Methods(http.MethodPost)
+	s.router.HandleFunc("/v1/imports/{userId}/apple-health", s.requireDevice(s.handleImport(importFormatAppleHealth))).Methods(http.MethodPost)
+	s.router.HandleFunc("/v1/imports/{userId}/google-fit", s.requireDevice(s.handleImport(importFormatGoogleFit))).Methods(http.MethodPost)
+	s.router.HandleFunc("/v1/imports/{userId}/calendar", s.requireDevice(s.handleCalendarImport)).Methods(http.MethodPost)
+	s.router.HandleFunc("/v1/imports/{jobId}", s.requireDevice(s.handleGetImport)).Methods(http.MethodGet)
+	s.router.HandleFunc("/v1/webhooks/partners/{partner}", s.handlePartnerWebhook).Methods(http.MethodPost)
+
+	s.internal.Use(s.loggingMiddleware)
+	s.internal.HandleFunc("/healthz", s.handleHealth).Methods(http.MethodGet)
+	s.internal.HandleFunc("/v1/devices/{userId}", requireUser(s.handleRegisterDevice)).Methods(http.MethodPost)
+	s.internal.HandleFunc("/v1/devices/{userId}", requireUser(s.handleListDevices)).Methods(http.MethodGet)
+	s.internal.HandleFunc("/v1/devices/{userId}/{deviceId}", requireUser(s.handleRevokeDevice)).Methods(http.MethodDelete)
+	s.internal.HandleFunc("/v1/consents/{userId}", requireUser(s.handleListConsents)).Methods(http.MethodGet)
+	s.internal.HandleFunc("/v1/consents/{userId}/{source}", requireUser(s.handleGrantConsent)).Methods(http.MethodPut)
+	s.internal.HandleFunc("/v1/consents/{userId}/{source}", requireUser(s.handleRevokeConsent)).Methods(http.MethodDelete)
+	s.internal.HandleFunc("/v1/encryption-keys/{userId}", requireUser(s.handleShredDataKey)).Methods(http.MethodDelete)
+	s.internal.HandleFunc("/v1/partners/{userId}", requireUser(s.handleListPartnerLinks)).Methods(http.MethodGet)
+	s.internal.HandleFunc("/v1/partners/{userId}/{partner}", requireUser(s.handleLinkPartner)).Methods(http.MethodPut)
+	s.internal.HandleFunc("/v1/partners/{userId}/{partner}", requireUser(s.handleUnlinkPartner)).Methods(http.MethodDelete)
 
 	return s
 }
@@ -175,39 +370,78 @@ func (s *server) handleEventIngestion(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
 		return
 	}
-	if payload.Metadata == nil {
-		payload.Metadata = map[string]interface{}{}
+	if err := bindDevice(r.Context(), &payload); err != nil {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+		return
 	}
 
-	eventID := uuid.NewString()
-	payload.EventID = eventID
+	// Idempotency-Key 헤더가 없으면 클라이언트가 보낸 event_id를 멱등 키로 사용한다.
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	if idempotencyKey == "" {
+		idempotencyKey = payload.EventID
+	}
 
-	if s.producer != nil {
-		bytes, err := json.Marshal(payload)
-		if err != nil {
-			s.logger.Errorw("failed to marshal payload for kafka", "error", err)
-		} else {
-			if err := s.producer.Publish(r.Context(), []byte(payload.UserID), bytes); err != nil {
-				s.logger.Errorw("failed to publish kafka message", "error", err)
-			}
-		}
+	results, err := s.ingest(r.Context(), []ingestItem{{Payload: payload, Key: idempotencyKey}})
+	if err != nil {
+		s.logger.Errorw("failed to persist activity event", "error", err)
+		writeIngestError(w, err)
+		return
 	}
 
-	if s.repo != nil {
-		err := s.repo.Save(r.Context(), repository.Event{
-			EventID:        eventID,
-			UserID:         payload.UserID,
-			Source:         payload.Source,
-			TimestampStart: payload.StartedAt,
-			TimestampEnd:   payload.EndedAt,
-			Metadata:       payload.Metadata,
+	result := results[0]
+	switch result.Status {
+	case ingestStatusRejected:
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
+			"error":  result.Reason,
+			"fields": result.Fields,
 		})
-		if err != nil {
-			s.logger.Errorw("failed to persist activity event", "error", err)
-		}
+		return
+	case ingestStatusConflict:
+		writeJSON(w, http.StatusConflict, map[string]string{"error": result.Reason})
+		return
+	case ingestStatusNoConsent:
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": result.Reason})
+		return
+	case ingestStatusDuplicate:
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "queued", "event_id": result.EventID})
+}
+
+// validate는 소스 레지스트리와 메타데이터 스키마로 이벤트를 검증합니다.
+func (s *server) validate(payload activityEvent) error {
+	return s.validator.Validate(validation.Event{
+		EventID:   payload.EventID,
+		UserID:    payload.UserID,
+		Source:    payload.Source,
+		StartedAt: payload.StartedAt,
+		EndedAt:   payload.EndedAt,
+		Metadata:  payload.Metadata,
+	})
+}
+
+// writeIngestError는 내구성 있는 저장(또는 Postgres 미사용 시 Kafka 발행) 실패를 5xx로 응답합니다.
+func writeIngestError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errPublishUnavailable) {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "event broker unavailable"})
+		return
 	}
+	writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to persist event"})
+}
 
-	writeJSON(w, http.StatusAccepted, map[string]string{"status": "queued", "event_id": eventID})
+// hashPayload는 event_id를 제외한 요청 본문의 SHA-256 해시를 반환합니다.
+// 같은 멱등 키로 들어온 재시도 요청이 원본과 동일한지 비교하는 데 사용합니다.
+func hashPayload(payload activityEvent) (string, error) {
+	payload.EventID = ""
+	payload.StartedAt = payload.StartedAt.UTC()
+	payload.EndedAt = payload.EndedAt.UTC()
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
 }
 
 func (s *server) loggingMiddleware(next http.Handler) http.Handler {
@@ -0,0 +1,144 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"daylog/services/common/config"
+	"daylog/services/common/messaging"
+	"daylog/services/ingestion/repository"
+
+	"go.uber.org/zap"
+)
+
+// purgeInterval은 발행 완료된 아웃박스 행을 정리하는 주기입니다.
+const purgeInterval = time.Hour
+
+// Relay는 ingestion_outbox에 쌓인 메시지를 Kafka로 전달하는 백그라운드 작업입니다.
+// 발행 후 sent_at을 기록하기 전에 프로세스가 죽으면 같은 메시지가 다시 발행될 수 있으므로(at-least-once),
+// 소비자는 event_id 기준으로 멱등하게 처리해야 합니다.
+// purges가 있으면 consent_purges의 동의 철회 삭제 기록도 같은 방식으로 activity.purge에 발행합니다.
+type Relay struct {
+	repo         *repository.EventRepository
+	producer     *messaging.Producer
+	purges       *messaging.Producer
+	logger       *zap.SugaredLogger
+	batchSize    int
+	pollInterval time.Duration
+	maxBackoff   time.Duration
+	retention    time.Duration
+}
+
+// NewRelay는 새로운 아웃박스 릴레이를 생성합니다. purges가 nil이면 동의 철회 삭제 기록은 발행하지 않습니다.
+func NewRelay(repo *repository.EventRepository, producer, purges *messaging.Producer, logger *zap.SugaredLogger, cfg config.IngestionConfig) *Relay {
+	if logger == nil {
+		logger = zap.NewNop().Sugar()
+	}
+	batchSize := cfg.OutboxBatchSize
+	if batchSize <= 0 {
+		batchSize = 200
+	}
+	pollInterval := cfg.OutboxPollInterval
+	if pollInterval <= 0 {
+		pollInterval = 500 * time.Millisecond
+	}
+	return &Relay{
+		repo:         repo,
+		producer:     producer,
+		purges:       purges,
+		logger:       logger,
+		batchSize:    batchSize,
+		pollInterval: pollInterval,
+		maxBackoff:   cfg.OutboxMaxBackoff,
+		retention:    cfg.OutboxRetention,
+	}
+}
+
+// Run은 ctx가 취소될 때까지 아웃박스를 주기적으로 비웁니다.
+func (r *Relay) Run(ctx context.Context) {
+	r.logger.Infow("starting outbox relay", "batch_size", r.batchSize, "poll_interval", r.pollInterval)
+
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	lastPurge := time.Now()
+	for {
+		r.drain(ctx)
+		r.drainPurges(ctx)
+
+		if r.retention > 0 && time.Since(lastPurge) >= purgeInterval {
+			if n, err := r.repo.PurgeSentOutbox(ctx, r.retention); err != nil {
+				r.logger.Errorw("failed to purge outbox", "error", err)
+			} else if n > 0 {
+				r.logger.Infow("purged sent outbox rows", "count", n)
+			}
+			lastPurge = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			r.logger.Infow("outbox relay stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain은 대기 중인 메시지가 batchSize보다 적게 남을 때까지 반복해서 발행합니다.
+func (r *Relay) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		// 종료 신호가 와도 이미 잠근 배치는 발행과 sent_at 기록까지 마치도록 취소를 분리한다.
+		batchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		n, err := r.repo.RelayOutbox(batchCtx, r.batchSize, r.maxBackoff, r.publish)
+		cancel()
+		if err != nil {
+			r.logger.Errorw("failed to relay outbox messages", "error", err)
+			return
+		}
+		if n > 0 {
+			r.logger.Debugw("relayed outbox messages", "count", n)
+		}
+		if n < r.batchSize {
+			return
+		}
+	}
+}
+
+// drainPurges는 대기 중인 동의 철회 삭제 기록을 발행합니다. 드문 요청이므로 한 배치만 처리한다.
+func (r *Relay) drainPurges(ctx context.Context) {
+	if r.purges == nil || ctx.Err() != nil {
+		return
+	}
+	batchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+	n, err := r.repo.RelayConsentPurges(batchCtx, r.batchSize, r.maxBackoff, r.publishPurges)
+	if err != nil {
+		r.logger.Errorw("failed to relay consent purges", "error", err)
+		return
+	}
+	if n > 0 {
+		r.logger.Infow("relayed consent purges", "count", n)
+	}
+}
+
+func (r *Relay) publishPurges(ctx context.Context, purges []repository.ConsentPurge) error {
+	batch := make([]messaging.Message, len(purges))
+	for i, p := range purges {
+		value, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("marshal consent purge: %w", err)
+		}
+		batch[i] = messaging.Message{Key: []byte(p.UserID), Value: value}
+	}
+	return r.purges.PublishBatch(ctx, batch)
+}
+
+func (r *Relay) publish(ctx context.Context, messages []repository.OutboxMessage) error {
+	batch := make([]messaging.Message, len(messages))
+	for i, m := range messages {
+		batch[i] = messaging.Message{Key: m.Key, Value: m.Payload}
+	}
+	return r.producer.PublishBatch(ctx, batch)
+}
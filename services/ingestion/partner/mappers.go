@@ -0,0 +1,113 @@
+package partner
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// 파트너 페이로드 형식
+const (
+	// FormatDaylog는 `/v1/events`와 같은 필드를 쓰는 기본 형식입니다.
+	FormatDaylog = "daylog"
+	// FormatSamples는 웨어러블 파트너가 흔히 보내는 건강 샘플 목록 형식입니다.
+	FormatSamples = "samples"
+)
+
+// partnerSource는 샘플 형식 이벤트가 기록되는 소스입니다.
+const partnerSource = "health"
+
+type daylogPayload struct {
+	Events []struct {
+		ID        string         `json:"id"`
+		UserID    string         `json:"user_id"`
+		Source    string         `json:"source"`
+		StartedAt time.Time      `json:"started_at"`
+		EndedAt   time.Time      `json:"ended_at"`
+		Metadata  map[string]any `json:"metadata"`
+	} `json:"events"`
+}
+
+// mapDaylog는 `{"events": [...]}` 형식을 그대로 변환합니다. 검증은 수집 파이프라인이 담당합니다.
+func mapDaylog(body []byte) ([]Event, error) {
+	var payload daylogPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("decode daylog payload: %w", err)
+	}
+
+	events := make([]Event, 0, len(payload.Events))
+	for _, e := range payload.Events {
+		events = append(events, Event{
+			ExternalID: e.ID,
+			UserID:     e.UserID,
+			Source:     e.Source,
+			StartedAt:  e.StartedAt,
+			EndedAt:    e.EndedAt,
+			Metadata:   e.Metadata,
+		})
+	}
+	return events, nil
+}
+
+type samplesPayload struct {
+	Samples []struct {
+		ID       string    `json:"id"`
+		UserID   string    `json:"user_id"`
+		Type     string    `json:"type"`
+		Start    time.Time `json:"start"`
+		End      time.Time `json:"end"`
+		Value    *float64  `json:"value"`
+		Activity string    `json:"activity"`
+		Device   string    `json:"device"`
+	} `json:"samples"`
+}
+
+// mapSamples는 `{"samples": [{"type": "steps", "value": 1200, ...}]}` 형식을 건강 이벤트로 변환합니다.
+// type은 health 스키마의 값(workout, sleep, steps, heart_rate, mindfulness)을 사용합니다.
+func mapSamples(body []byte) ([]Event, error) {
+	var payload samplesPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("decode samples payload: %w", err)
+	}
+
+	events := make([]Event, 0, len(payload.Samples))
+	for _, s := range payload.Samples {
+		metadata := map[string]any{"type": s.Type}
+		if s.Device != "" {
+			metadata["device_name"] = s.Device
+		}
+
+		switch s.Type {
+		case "steps":
+			if s.Value != nil {
+				metadata["step_count"] = int64(math.Round(*s.Value))
+			}
+		case "heart_rate":
+			if s.Value != nil {
+				metadata["heart_rate_bpm"] = *s.Value
+			}
+		case "workout":
+			if s.Activity != "" {
+				metadata["workout_type"] = s.Activity
+			}
+			if s.Value != nil {
+				metadata["energy_kcal"] = *s.Value
+			}
+		case "sleep":
+			if s.Activity != "" {
+				metadata["sleep_stage"] = s.Activity
+			}
+		}
+
+		events = append(events, Event{
+			ExternalID: s.ID,
+			UserID:     s.UserID,
+			Source:     partnerSource,
+			StartedAt:  s.Start,
+			EndedAt:    s.End,
+			Metadata:   metadata,
+		})
+	}
+	return events, nil
+}
@@ -0,0 +1,111 @@
+package partner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxActiveSecrets는 파트너별 동시에 유효한 비밀 키 수입니다(현재 키와 교체 직전 키).
+const maxActiveSecrets = 2
+
+// Event는 파트너 페이로드에서 변환된 활동 이벤트입니다.
+// ExternalID는 파트너 측 레코드 식별자로, 결정적 event_id를 만드는 데 사용합니다.
+type Event struct {
+	ExternalID string
+	UserID     string
+	Source     string
+	StartedAt  time.Time
+	EndedAt    time.Time
+	Metadata   map[string]any
+}
+
+// Mapper는 파트너 고유 형식의 요청 본문을 이벤트 목록으로 변환합니다.
+type Mapper func(body []byte) ([]Event, error)
+
+// mappers는 파트너 페이로드 형식 이름과 변환기의 매핑입니다.
+var mappers = map[string]Mapper{
+	FormatDaylog:  mapDaylog,
+	FormatSamples: mapSamples,
+}
+
+// Partner는 웹훅을 보낼 수 있는 외부 연동 파트너입니다.
+type Partner struct {
+	Name    string
+	Format  string
+	Secrets []string
+	Map     Mapper
+}
+
+// Registry는 설정된 파트너를 이름으로 조회합니다.
+type Registry struct {
+	partners map[string]*Partner
+}
+
+// NewRegistry는 파트너별 비밀 키와 페이로드 형식 설정으로 Registry를 생성합니다.
+// secrets 값은 `현재키|이전키`처럼 `|`로 구분하며 최대 두 개까지 허용합니다.
+// formats에 없는 파트너는 Daylog 기본 형식을 사용합니다.
+func NewRegistry(secrets, formats map[string]string) (*Registry, error) {
+	partners := make(map[string]*Partner, len(secrets))
+	for name, raw := range secrets {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("partner name must not be empty")
+		}
+
+		var keys []string
+		for _, key := range strings.Split(raw, "|") {
+			if key = strings.TrimSpace(key); key != "" {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("partner %s: no secret configured", name)
+		}
+		if len(keys) > maxActiveSecrets {
+			return nil, fmt.Errorf("partner %s: at most %d active secrets allowed", name, maxActiveSecrets)
+		}
+
+		format := FormatDaylog
+		if f, ok := formats[name]; ok {
+			format = f
+		}
+		mapper, ok := mappers[format]
+		if !ok {
+			return nil, fmt.Errorf("partner %s: unknown payload format %q", name, format)
+		}
+
+		partners[name] = &Partner{Name: name, Format: format, Secrets: keys, Map: mapper}
+	}
+
+	for name := range formats {
+		if _, ok := partners[name]; !ok {
+			return nil, fmt.Errorf("partner %s: format configured without secret", name)
+		}
+	}
+
+	return &Registry{partners: partners}, nil
+}
+
+// Lookup은 이름에 해당하는 파트너를 반환합니다.
+func (r *Registry) Lookup(name string) (*Partner, bool) {
+	if r == nil {
+		return nil, false
+	}
+	p, ok := r.partners[name]
+	return p, ok
+}
+
+// Names는 등록된 파트너 이름을 정렬해 반환합니다.
+func (r *Registry) Names() []string {
+	if r == nil {
+		return nil
+	}
+	names := make([]string, 0, len(r.partners))
+	for name := range r.partners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
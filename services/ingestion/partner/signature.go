@@ -0,0 +1,93 @@
+package partner
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader는 파트너 웹훅 서명이 담기는 HTTP 헤더입니다.
+// 형식은 `t=<unix 초>,v1=<hex HMAC-SHA256>`이며, 서명 대상은 `<t>.<요청 본문>`입니다.
+// 비밀 키 교체 중에는 v1 값을 여러 개 보낼 수 있습니다.
+const SignatureHeader = "Daylog-Signature"
+
+var (
+	ErrNoSignature         = errors.New("signature header missing")
+	ErrInvalidHeader       = errors.New("signature header malformed")
+	ErrTimestampOutOfRange = errors.New("signature timestamp outside tolerance")
+	ErrNoValidSignature    = errors.New("no valid signature found")
+)
+
+// Sign은 본문과 타임스탬프로 SignatureHeader 값을 만듭니다. 파트너 연동 도구와 점검용입니다.
+func Sign(payload []byte, secret string, t time.Time) string {
+	ts := t.Unix()
+	return fmt.Sprintf("t=%d,v1=%s", ts, hex.EncodeToString(computeSignature(payload, secret, ts)))
+}
+
+// Verify는 서명 헤더를 검사합니다. 타임스탬프가 now 기준 tolerance를 벗어나면 재전송으로 보고 거절하며,
+// 서명은 secrets 중 하나와 일치하면 유효합니다.
+func Verify(payload []byte, header string, secrets []string, tolerance time.Duration, now time.Time) error {
+	if header == "" {
+		return ErrNoSignature
+	}
+
+	var (
+		ts         int64
+		hasTS      bool
+		signatures [][]byte
+	)
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return ErrInvalidHeader
+		}
+		switch key {
+		case "t":
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return ErrInvalidHeader
+			}
+			ts, hasTS = parsed, true
+		case "v1":
+			sig, err := hex.DecodeString(value)
+			if err != nil {
+				continue
+			}
+			signatures = append(signatures, sig)
+		}
+	}
+	if !hasTS {
+		return ErrInvalidHeader
+	}
+	if len(signatures) == 0 {
+		return ErrNoValidSignature
+	}
+
+	age := now.Sub(time.Unix(ts, 0))
+	if tolerance > 0 && (age > tolerance || age < -tolerance) {
+		return ErrTimestampOutOfRange
+	}
+
+	for _, secret := range secrets {
+		expected := computeSignature(payload, secret, ts)
+		for _, sig := range signatures {
+			if hmac.Equal(expected, sig) {
+				return nil
+			}
+		}
+	}
+	return ErrNoValidSignature
+}
+
+func computeSignature(payload []byte, secret string, ts int64) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(ts, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleLinkPartner는 사용자가 외부 파트너를 연동했음을 기록합니다. 연동된 사용자의 이벤트만 파트너 웹훅으로 받습니다.
+func (s *server) handleLinkPartner(w http.ResponseWriter, r *http.Request) {
+	userID, name, ok := s.partnerLinkVars(w, r)
+	if !ok {
+		return
+	}
+
+	link, err := s.repo.LinkPartner(r.Context(), userID, name)
+	if err != nil {
+		s.logger.Errorw("failed to link partner", "error", err, "user_id", userID, "partner", name)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to link partner"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, link)
+}
+
+// handleListPartnerLinks는 사용자의 파트너 연동 기록을 반환합니다.
+func (s *server) handleListPartnerLinks(w http.ResponseWriter, r *http.Request) {
+	if s.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "partner links require postgres"})
+		return
+	}
+
+	userID := mux.Vars(r)["userId"]
+	if _, err := uuid.Parse(userID); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "userId must be a UUID"})
+		return
+	}
+
+	links, err := s.repo.ListPartnerLinks(r.Context(), userID)
+	if err != nil {
+		s.logger.Errorw("failed to list partner links", "error", err, "user_id", userID)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to list partner links"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, links)
+}
+
+// handleUnlinkPartner는 파트너 연동을 해제합니다. 이후 그 파트너가 보낸 사용자의 이벤트는 거절됩니다.
+func (s *server) handleUnlinkPartner(w http.ResponseWriter, r *http.Request) {
+	userID, name, ok := s.partnerLinkVars(w, r)
+	if !ok {
+		return
+	}
+
+	unlinked, err := s.repo.UnlinkPartner(r.Context(), userID, name)
+	if err != nil {
+		s.logger.Errorw("failed to unlink partner", "error", err, "user_id", userID, "partner", name)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to unlink partner"})
+		return
+	}
+	if !unlinked {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "partner link not found"})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// partnerLinkVars는 경로의 userId와 등록된 파트너 이름을 검증합니다.
+func (s *server) partnerLinkVars(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	if s.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "partner links require postgres"})
+		return "", "", false
+	}
+
+	vars := mux.Vars(r)
+	userID := vars["userId"]
+	if _, err := uuid.Parse(userID); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "userId must be a UUID"})
+		return "", "", false
+	}
+	p, ok := s.partners.Lookup(vars["partner"])
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown partner"})
+		return "", "", false
+	}
+	return userID, p.Name, true
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"daylog/services/common/messaging"
+	"daylog/services/ingestion/repository"
+	"daylog/services/ingestion/validation"
+
+	"github.com/google/uuid"
+)
+
+// 수집 항목 처리 상태
+const (
+	ingestStatusAccepted  = "accepted"
+	ingestStatusDuplicate = "duplicate"
+	ingestStatusRejected  = "rejected"
+	ingestStatusConflict  = "conflict"
+	ingestStatusNoConsent = "no_consent"
+)
+
+// errPublishUnavailable은 Postgres 없이 운영 중일 때 Kafka 직접 발행이 실패했음을 나타냅니다.
+var errPublishUnavailable = errors.New("event publish failed")
+
+// ingestItem은 수집 파이프라인에 들어가는 단일 이벤트와 멱등 키입니다.
+type ingestItem struct {
+	Payload activityEvent
+	Key     string
+}
+
+// ingestResult는 수집 파이프라인의 항목별 처리 결과입니다.
+type ingestResult struct {
+	EventID string
+	Status  string
+	Reason  string
+	Fields  []validation.FieldError
+}
+
+// ingest는 모든 수집 경로가 공유하는 검증·저장·발행 파이프라인입니다.
+// 검증을 통과한 항목은 activity_events와 아웃박스에 한 트랜잭션으로 저장되고, Kafka 발행은 아웃박스 릴레이가 담당합니다.
+// 반환되는 error는 내구성 있는 저장 자체가 실패한 경우에만 non-nil입니다.
+func (s *server) ingest(ctx context.Context, items []ingestItem) ([]ingestResult, error) {
+	results := make([]ingestResult, len(items))
+	accepted := make([]activityEvent, 0, len(items))
+	batch := make([]repository.BatchItem, 0, len(items))
+	indexes := make([]int, 0, len(items))
+
+	consents, err := s.loadConsents(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+	timezones, err := s.loadTimezones(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i, item := range items {
+		payload := item.Payload
+		if payload.Metadata == nil {
+			payload.Metadata = map[string]interface{}{}
+		}
+		timeContext := timezones.normalise(&payload, now, s.cfg.Ingestion.ClockSkewThreshold)
+
+		if err := s.validate(payload); err != nil {
+			results[i] = rejectedResult(err)
+			continue
+		}
+		if reason := consents.check(s.validator, payload); reason != "" {
+			results[i] = ingestResult{Status: ingestStatusNoConsent, Reason: reason}
+			continue
+		}
+
+		requestHash, err := hashPayload(payload)
+		if err != nil {
+			results[i] = ingestResult{Status: ingestStatusRejected, Reason: "invalid payload"}
+			continue
+		}
+		if payload.EventID == "" {
+			payload.EventID = uuid.NewString()
+		}
+		payload.Metadata = annotateTime(payload.Metadata, timeContext)
+		if payload.Metadata, err = s.encryptMetadata(ctx, payload); err != nil {
+			return nil, err
+		}
+
+		event, err := payload.toRepositoryEvent()
+		if err != nil {
+			results[i] = ingestResult{Status: ingestStatusRejected, Reason: "invalid payload"}
+			continue
+		}
+
+		accepted = append(accepted, payload)
+		batch = append(batch, repository.BatchItem{
+			Key:         item.Key,
+			RequestHash: requestHash,
+			Event:       event,
+		})
+		indexes = append(indexes, i)
+	}
+
+	if len(batch) == 0 {
+		return results, nil
+	}
+
+	if s.repo == nil {
+		if err := s.publishDirect(ctx, accepted); err != nil {
+			return nil, err
+		}
+		for j, payload := range accepted {
+			results[indexes[j]] = ingestResult{EventID: payload.EventID, Status: ingestStatusAccepted}
+		}
+		return results, nil
+	}
+
+	saved, err := s.repo.SaveBatch(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+	for j, res := range saved {
+		switch {
+		case res.Conflict:
+			results[indexes[j]] = ingestResult{
+				Status: ingestStatusConflict,
+				Reason: "idempotency key already used with a different payload",
+			}
+		case res.Replayed:
+			results[indexes[j]] = ingestResult{EventID: res.EventID, Status: ingestStatusDuplicate}
+		default:
+			results[indexes[j]] = ingestResult{EventID: res.EventID, Status: ingestStatusAccepted}
+		}
+	}
+
+	return results, nil
+}
+
+// publishDirect는 Postgres가 비활성화된 환경에서 아웃박스 없이 Kafka로 바로 발행합니다.
+func (s *server) publishDirect(ctx context.Context, payloads []activityEvent) error {
+	if s.producer == nil {
+		return nil
+	}
+
+	messages := make([]messaging.Message, 0, len(payloads))
+	for _, payload := range payloads {
+		value, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("marshal payload for kafka: %w", err)
+		}
+		messages = append(messages, messaging.Message{Key: []byte(payload.UserID), Value: value})
+	}
+
+	if err := s.producer.PublishBatch(ctx, messages); err != nil {
+		s.logger.Errorw("failed to publish kafka messages", "error", err, "count", len(messages))
+		return errPublishUnavailable
+	}
+	return nil
+}
+
+// ingestUserIDs는 수집 항목에 등장하는 사용자 ID를 중복 없이 반환합니다.
+// 형식이 잘못된 user_id는 검증 단계에서 거절되므로 조회 대상에서 제외한다.
+func ingestUserIDs(items []ingestItem) []string {
+	seen := make(map[string]bool)
+	userIDs := make([]string, 0, 1)
+	for _, item := range items {
+		id := item.Payload.UserID
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, err := uuid.Parse(id); err == nil {
+			userIDs = append(userIDs, id)
+		}
+	}
+	return userIDs
+}
+
+func rejectedResult(err error) ingestResult {
+	var verr *validation.Error
+	if errors.As(err, &verr) {
+		return ingestResult{Status: ingestStatusRejected, Reason: "validation failed", Fields: verr.Fields}
+	}
+	return ingestResult{Status: ingestStatusRejected, Reason: err.Error()}
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Consent는 source_consents 테이블의 소스 종류별 수집 동의입니다.
+type Consent struct {
+	UserID    string     `json:"user_id"`
+	Source    string     `json:"source"`
+	Scope     string     `json:"scope"`
+	GrantedAt time.Time  `json:"granted_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// ConsentPurge는 동의 철회로 삭제한 원본 이벤트의 기록입니다.
+// JSON 형태 그대로 activity.purge 메시지로 발행되어 타임라인이 파생 데이터를 정리한다.
+type ConsentPurge struct {
+	ID          int64     `json:"-"`
+	UserID      string    `json:"user_id"`
+	Kind        string    `json:"kind"`
+	Sources     []string  `json:"sources"`
+	Purged      int64     `json:"purged"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// GrantConsent는 동의를 기록합니다. 이미 유효한 동의가 있으면 scope만 갱신하고 granted_at은 유지하며,
+// 철회된 동의를 다시 부여하면 granted_at을 현재 시각으로 새로 설정합니다.
+func (r *EventRepository) GrantConsent(ctx context.Context, userID, source, scope string) (Consent, error) {
+	if r == nil || r.pool == nil {
+		return Consent{}, fmt.Errorf("event repository not initialised")
+	}
+
+	const query = `
+		INSERT INTO source_consents (
+			user_id,
+			source,
+			scope
+		) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, source) DO UPDATE
+		   SET scope = EXCLUDED.scope,
+		       granted_at = CASE WHEN source_consents.revoked_at IS NULL
+		                         THEN source_consents.granted_at
+		                         ELSE NOW() END,
+		       revoked_at = NULL,
+		       updated_at = NOW()
+		RETURNING granted_at, updated_at
+	`
+
+	c := Consent{UserID: userID, Source: source, Scope: scope}
+	if err := r.pool.QueryRow(ctx, query, userID, source, scope).Scan(&c.GrantedAt, &c.UpdatedAt); err != nil {
+		return Consent{}, fmt.Errorf("upsert source_consent: %w", err)
+	}
+	return c, nil
+}
+
+// ListConsents는 사용자의 동의 기록(철회 포함)을 반환합니다.
+func (r *EventRepository) ListConsents(ctx context.Context, userID string) ([]Consent, error) {
+	if r == nil || r.pool == nil {
+		return nil, fmt.Errorf("event repository not initialised")
+	}
+
+	const query = `
+		SELECT user_id,
+		       source,
+		       scope,
+		       granted_at,
+		       revoked_at,
+		       updated_at
+		  FROM source_consents
+		 WHERE user_id = $1
+		 ORDER BY source
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query source_consents: %w", err)
+	}
+	defer rows.Close()
+
+	consents := []Consent{}
+	for rows.Next() {
+		var c Consent
+		if err := rows.Scan(&c.UserID, &c.Source, &c.Scope, &c.GrantedAt, &c.RevokedAt, &c.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan source_consent row: %w", err)
+		}
+		consents = append(consents, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate source_consents: %w", err)
+	}
+	return consents, nil
+}
+
+// ActiveConsents는 사용자별로 유효한 동의를 소스 종류 기준으로 반환합니다.
+func (r *EventRepository) ActiveConsents(ctx context.Context, userIDs []string) (map[string]map[string]Consent, error) {
+	if r == nil || r.pool == nil {
+		return nil, fmt.Errorf("event repository not initialised")
+	}
+
+	const query = `
+		SELECT user_id,
+		       source,
+		       scope,
+		       granted_at,
+		       updated_at
+		  FROM source_consents
+		 WHERE user_id = ANY($1::text[]::uuid[])
+		   AND revoked_at IS NULL
+	`
+
+	rows, err := r.pool.Query(ctx, query, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query active consents: %w", err)
+	}
+	defer rows.Close()
+
+	consents := make(map[string]map[string]Consent, len(userIDs))
+	for rows.Next() {
+		var c Consent
+		if err := rows.Scan(&c.UserID, &c.Source, &c.Scope, &c.GrantedAt, &c.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan active consent row: %w", err)
+		}
+		if consents[c.UserID] == nil {
+			consents[c.UserID] = map[string]Consent{}
+		}
+		consents[c.UserID][c.Source] = c
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate active consents: %w", err)
+	}
+	return consents, nil
+}
+
+// RevokeConsent는 동의를 철회합니다. purgeSources가 비어 있지 않으면 같은 트랜잭션에서
+// 해당 소스 이름의 activity_events와 멱등 키를 삭제하고(아웃박스 행은 외래 키로 함께 삭제),
+// 삭제한 이벤트가 있으면 타임라인이 파생 데이터를 정리하도록 consent_purges에 발행할 기록을 남깁니다.
+// 유효한 동의가 없으면 found는 false입니다.
+func (r *EventRepository) RevokeConsent(ctx context.Context, userID, source string, purgeSources []string) (found bool, purged int64, err error) {
+	if r == nil || r.pool == nil {
+		return false, 0, fmt.Errorf("event repository not initialised")
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // nolint:errcheck
+
+	const revoke = `
+		UPDATE source_consents
+		   SET revoked_at = NOW(),
+		       updated_at = NOW()
+		 WHERE user_id = $1
+		   AND source = $2
+		   AND revoked_at IS NULL
+	`
+
+	ct, err := tx.Exec(ctx, revoke, userID, source)
+	if err != nil {
+		return false, 0, fmt.Errorf("revoke source_consent: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return false, 0, nil
+	}
+
+	if len(purgeSources) > 0 {
+		const purgeKeys = `
+			DELETE FROM ingestion_idempotency_keys k
+			 USING activity_events e
+			 WHERE k.event_id = e.event_id
+			   AND e.user_id = $1
+			   AND e.source = ANY($2)
+		`
+		if _, err := tx.Exec(ctx, purgeKeys, userID, purgeSources); err != nil {
+			return false, 0, fmt.Errorf("purge idempotency keys: %w", err)
+		}
+
+		const purgeEvents = `
+			DELETE FROM activity_events
+			 WHERE user_id = $1
+			   AND source = ANY($2)
+		`
+		ct, err := tx.Exec(ctx, purgeEvents, userID, purgeSources)
+		if err != nil {
+			return false, 0, fmt.Errorf("purge activity_events: %w", err)
+		}
+		purged = ct.RowsAffected()
+
+		if purged > 0 {
+			const record = `
+				INSERT INTO consent_purges (
+					user_id,
+					kind,
+					sources,
+					purged
+				) VALUES ($1, $2, $3, $4)
+			`
+			if _, err := tx.Exec(ctx, record, userID, source, purgeSources, purged); err != nil {
+				return false, 0, fmt.Errorf("insert consent_purges: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, 0, fmt.Errorf("commit transaction: %w", err)
+	}
+	return true, purged, nil
+}
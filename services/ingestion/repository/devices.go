@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Device는 devices 테이블의 연동 기기입니다. 토큰 해시는 응답에 노출하지 않습니다.
+type Device struct {
+	DeviceID  string     `json:"device_id"`
+	UserID    string     `json:"user_id"`
+	Platform  string     `json:"platform"`
+	Name      string     `json:"name"`
+	CreatedAt time.Time  `json:"created_at"`
+	LastSeen  *time.Time `json:"last_seen,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// RegisterDevice는 기기를 등록하고 해시된 수집 토큰을 저장합니다.
+func (r *EventRepository) RegisterDevice(ctx context.Context, device Device, tokenHash string) (Device, error) {
+	if r == nil || r.pool == nil {
+		return Device{}, fmt.Errorf("event repository not initialised")
+	}
+
+	const query = `
+		INSERT INTO devices (
+			device_id,
+			user_id,
+			platform,
+			name,
+			token_hash
+		) VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at
+	`
+
+	if err := r.pool.QueryRow(ctx, query,
+		device.DeviceID,
+		device.UserID,
+		device.Platform,
+		device.Name,
+		tokenHash,
+	).Scan(&device.CreatedAt); err != nil {
+		return Device{}, fmt.Errorf("insert device: %w", err)
+	}
+	return device, nil
+}
+
+// ListDevices는 사용자의 기기를 최근 등록 순으로 반환합니다.
+func (r *EventRepository) ListDevices(ctx context.Context, userID string, includeRevoked bool) ([]Device, error) {
+	if r == nil || r.pool == nil {
+		return nil, fmt.Errorf("event repository not initialised")
+	}
+
+	const query = `
+		SELECT device_id,
+		       user_id,
+		       platform,
+		       name,
+		       created_at,
+		       last_seen,
+		       revoked_at
+		  FROM devices
+		 WHERE user_id = $1
+		   AND ($2 OR revoked_at IS NULL)
+		 ORDER BY created_at DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, includeRevoked)
+	if err != nil {
+		return nil, fmt.Errorf("query devices: %w", err)
+	}
+	defer rows.Close()
+
+	devices := []Device{}
+	for rows.Next() {
+		var d Device
+		if err := rows.Scan(
+			&d.DeviceID,
+			&d.UserID,
+			&d.Platform,
+			&d.Name,
+			&d.CreatedAt,
+			&d.LastSeen,
+			&d.RevokedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan device row: %w", err)
+		}
+		devices = append(devices, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate devices: %w", err)
+	}
+	return devices, nil
+}
+
+// RevokeDevice는 기기를 폐기합니다. 해당 사용자의 활성 기기가 없으면 false를 반환합니다.
+func (r *EventRepository) RevokeDevice(ctx context.Context, userID, deviceID string) (bool, error) {
+	if r == nil || r.pool == nil {
+		return false, fmt.Errorf("event repository not initialised")
+	}
+
+	const query = `
+		UPDATE devices
+		   SET revoked_at = NOW()
+		 WHERE device_id = $1
+		   AND user_id = $2
+		   AND revoked_at IS NULL
+	`
+
+	ct, err := r.pool.Exec(ctx, query, deviceID, userID)
+	if err != nil {
+		return false, fmt.Errorf("revoke device: %w", err)
+	}
+	return ct.RowsAffected() > 0, nil
+}
+
+// AuthenticateDevice는 토큰 해시로 활성 기기를 찾고 last_seen을 갱신합니다.
+// 요청마다 쓰기가 발생하지 않도록 last_seen은 1분 단위로만 갱신합니다. 기기가 없으면 nil을 반환합니다.
+func (r *EventRepository) AuthenticateDevice(ctx context.Context, tokenHash string) (*Device, error) {
+	if r == nil || r.pool == nil {
+		return nil, fmt.Errorf("event repository not initialised")
+	}
+
+	const query = `
+		SELECT device_id,
+		       user_id,
+		       platform,
+		       name,
+		       created_at,
+		       last_seen
+		  FROM devices
+		 WHERE token_hash = $1
+		   AND revoked_at IS NULL
+	`
+
+	var d Device
+	if err := r.pool.QueryRow(ctx, query, tokenHash).Scan(
+		&d.DeviceID,
+		&d.UserID,
+		&d.Platform,
+		&d.Name,
+		&d.CreatedAt,
+		&d.LastSeen,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select device: %w", err)
+	}
+
+	if d.LastSeen == nil || time.Since(*d.LastSeen) >= time.Minute {
+		const touch = `
+			UPDATE devices
+			   SET last_seen = NOW()
+			 WHERE device_id = $1
+			RETURNING last_seen
+		`
+		if err := r.pool.QueryRow(ctx, touch, d.DeviceID).Scan(&d.LastSeen); err != nil {
+			return nil, fmt.Errorf("update device last_seen: %w", err)
+		}
+	}
+
+	return &d, nil
+}
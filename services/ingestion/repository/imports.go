@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// 가져오기 작업 상태
+const (
+	ImportStatusPending   = "pending"
+	ImportStatusRunning   = "running"
+	ImportStatusSucceeded = "succeeded"
+	ImportStatusFailed    = "failed"
+)
+
+// ImportJob은 import_jobs 테이블의 백필 작업 진행 상황입니다.
+type ImportJob struct {
+	JobID      string     `json:"job_id"`
+	UserID     string     `json:"user_id"`
+	Format     string     `json:"format"`
+	Status     string     `json:"status"`
+	Processed  int        `json:"processed"`
+	Accepted   int        `json:"accepted"`
+	Duplicate  int        `json:"duplicate"`
+	Rejected   int        `json:"rejected"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// CreateImportJob은 pending 상태의 가져오기 작업을 생성합니다.
+func (r *EventRepository) CreateImportJob(ctx context.Context, jobID, userID, format string) (ImportJob, error) {
+	if r == nil || r.pool == nil {
+		return ImportJob{}, fmt.Errorf("event repository not initialised")
+	}
+
+	const query = `
+		INSERT INTO import_jobs (
+			job_id,
+			user_id,
+			format,
+			status
+		) VALUES ($1, $2, $3, $4)
+		RETURNING created_at, updated_at
+	`
+
+	job := ImportJob{JobID: jobID, UserID: userID, Format: format, Status: ImportStatusPending}
+	if err := r.pool.QueryRow(ctx, query, jobID, userID, format, ImportStatusPending).Scan(&job.CreatedAt, &job.UpdatedAt); err != nil {
+		return ImportJob{}, fmt.Errorf("insert import_job: %w", err)
+	}
+	return job, nil
+}
+
+// UpdateImportJob은 작업 상태와 누적 카운터를 갱신합니다. 종료 상태이면 finished_at도 기록합니다.
+func (r *EventRepository) UpdateImportJob(ctx context.Context, job ImportJob) error {
+	if r == nil || r.pool == nil {
+		return fmt.Errorf("event repository not initialised")
+	}
+
+	const query = `
+		UPDATE import_jobs
+		   SET status = $2,
+		       processed = $3,
+		       accepted = $4,
+		       duplicate = $5,
+		       rejected = $6,
+		       error = NULLIF($7, ''),
+		       updated_at = NOW(),
+		       finished_at = CASE WHEN $2 IN ('succeeded', 'failed') THEN NOW() ELSE finished_at END
+		 WHERE job_id = $1
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		job.JobID,
+		job.Status,
+		job.Processed,
+		job.Accepted,
+		job.Duplicate,
+		job.Rejected,
+		job.Error,
+	)
+	if err != nil {
+		return fmt.Errorf("update import_job: %w", err)
+	}
+	return nil
+}
+
+// GetImportJob은 작업을 조회합니다. 없으면 nil을 반환합니다.
+func (r *EventRepository) GetImportJob(ctx context.Context, jobID string) (*ImportJob, error) {
+	if r == nil || r.pool == nil {
+		return nil, fmt.Errorf("event repository not initialised")
+	}
+
+	const query = `
+		SELECT job_id,
+		       user_id,
+		       format,
+		       status,
+		       processed,
+		       accepted,
+		       duplicate,
+		       rejected,
+		       COALESCE(error, ''),
+		       created_at,
+		       updated_at,
+		       finished_at
+		  FROM import_jobs
+		 WHERE job_id = $1
+	`
+
+	var job ImportJob
+	if err := r.pool.QueryRow(ctx, query, jobID).Scan(
+		&job.JobID,
+		&job.UserID,
+		&job.Format,
+		&job.Status,
+		&job.Processed,
+		&job.Accepted,
+		&job.Duplicate,
+		&job.Rejected,
+		&job.Error,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&job.FinishedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select import_job: %w", err)
+	}
+	return &job, nil
+}
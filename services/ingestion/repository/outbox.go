@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// OutboxMessage는 ingestion_outbox 테이블에서 발행 대기 중인 메시지입니다.
+type OutboxMessage struct {
+	ID       int64
+	EventID  string
+	Key      []byte
+	Payload  []byte
+	Attempts int
+}
+
+func enqueueOutbox(ctx context.Context, tx pgx.Tx, event Event) error {
+	if len(event.Message) == 0 {
+		return fmt.Errorf("outbox message for event %s is empty", event.EventID)
+	}
+
+	const query = `
+		INSERT INTO ingestion_outbox (
+			event_id,
+			message_key,
+			payload
+		) VALUES ($1, $2, $3)
+	`
+
+	if _, err := tx.Exec(ctx, query, event.EventID, []byte(event.UserID), event.Message); err != nil {
+		return fmt.Errorf("insert ingestion_outbox: %w", err)
+	}
+	return nil
+}
+
+// RelayOutbox는 발행 대기 중인 메시지를 최대 limit건 잠그고 publish에 넘깁니다.
+// publish가 성공하면 sent_at을 기록하고, 실패하면 시도 횟수를 늘리고 지수 백오프로 다음 시도 시각을 미룹니다.
+// 잠금은 FOR UPDATE SKIP LOCKED를 사용하므로 여러 인스턴스가 동시에 릴레이해도 같은 행을 중복 처리하지 않습니다.
+func (r *EventRepository) RelayOutbox(ctx context.Context, limit int, maxBackoff time.Duration, publish func(context.Context, []OutboxMessage) error) (int, error) {
+	if r == nil || r.pool == nil {
+		return 0, fmt.Errorf("event repository not initialised")
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // nolint:errcheck
+
+	const claim = `
+		SELECT id,
+		       event_id,
+		       message_key,
+		       payload,
+		       attempts
+		  FROM ingestion_outbox
+		 WHERE sent_at IS NULL
+		   AND next_attempt_at <= NOW()
+		 ORDER BY id
+		 LIMIT $1
+		 FOR UPDATE SKIP LOCKED
+	`
+
+	rows, err := tx.Query(ctx, claim, limit)
+	if err != nil {
+		return 0, fmt.Errorf("query ingestion_outbox: %w", err)
+	}
+
+	var (
+		messages []OutboxMessage
+		ids      []int64
+	)
+	for rows.Next() {
+		var msg OutboxMessage
+		if err := rows.Scan(&msg.ID, &msg.EventID, &msg.Key, &msg.Payload, &msg.Attempts); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan ingestion_outbox row: %w", err)
+		}
+		messages = append(messages, msg)
+		ids = append(ids, msg.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterate ingestion_outbox: %w", err)
+	}
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	if publishErr := publish(ctx, messages); publishErr != nil {
+		// 지수는 먼저 제한한다. 시도가 오래 쌓이면 power(2, attempts)가 interval 범위를 넘어 LEAST에 닿기 전에 UPDATE가 실패한다.
+		const retry = `
+			UPDATE ingestion_outbox
+			   SET attempts = attempts + 1,
+			       last_error = $2,
+			       next_attempt_at = NOW() + make_interval(secs => LEAST(power(2, LEAST(attempts, 30)), $3))
+			 WHERE id = ANY($1)
+		`
+		if _, err := tx.Exec(ctx, retry, ids, publishErr.Error(), maxBackoff.Seconds()); err != nil {
+			return 0, fmt.Errorf("schedule ingestion_outbox retry: %w", err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return 0, fmt.Errorf("commit transaction: %w", err)
+		}
+		return 0, publishErr
+	}
+
+	const markSent = `
+		UPDATE ingestion_outbox
+		   SET sent_at = NOW(),
+		       attempts = attempts + 1,
+		       last_error = NULL
+		 WHERE id = ANY($1)
+	`
+	if _, err := tx.Exec(ctx, markSent, ids); err != nil {
+		return 0, fmt.Errorf("mark ingestion_outbox sent: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return len(messages), nil
+}
+
+// RelayConsentPurges는 발행 대기 중인 동의 철회 삭제 기록을 최대 limit건 잠그고 publish에 넘깁니다.
+// 재시도와 잠금 방식은 RelayOutbox와 같으며, 발행된 행은 감사 기록으로 남기고 sent_at만 기록합니다.
+func (r *EventRepository) RelayConsentPurges(ctx context.Context, limit int, maxBackoff time.Duration, publish func(context.Context, []ConsentPurge) error) (int, error) {
+	if r == nil || r.pool == nil {
+		return 0, fmt.Errorf("event repository not initialised")
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // nolint:errcheck
+
+	const claim = `
+		SELECT id,
+		       user_id::text,
+		       kind,
+		       sources,
+		       purged,
+		       requested_at
+		  FROM consent_purges
+		 WHERE sent_at IS NULL
+		   AND next_attempt_at <= NOW()
+		 ORDER BY id
+		 LIMIT $1
+		 FOR UPDATE SKIP LOCKED
+	`
+
+	rows, err := tx.Query(ctx, claim, limit)
+	if err != nil {
+		return 0, fmt.Errorf("query consent_purges: %w", err)
+	}
+
+	var (
+		purges []ConsentPurge
+		ids    []int64
+	)
+	for rows.Next() {
+		var p ConsentPurge
+		if err := rows.Scan(&p.ID, &p.UserID, &p.Kind, &p.Sources, &p.Purged, &p.RequestedAt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan consent_purges row: %w", err)
+		}
+		purges = append(purges, p)
+		ids = append(ids, p.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterate consent_purges: %w", err)
+	}
+	if len(purges) == 0 {
+		return 0, nil
+	}
+
+	if publishErr := publish(ctx, purges); publishErr != nil {
+		const retry = `
+			UPDATE consent_purges
+			   SET attempts = attempts + 1,
+			       last_error = $2,
+			       next_attempt_at = NOW() + make_interval(secs => LEAST(power(2, LEAST(attempts, 30)), $3))
+			 WHERE id = ANY($1)
+		`
+		if _, err := tx.Exec(ctx, retry, ids, publishErr.Error(), maxBackoff.Seconds()); err != nil {
+			return 0, fmt.Errorf("schedule consent_purges retry: %w", err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return 0, fmt.Errorf("commit transaction: %w", err)
+		}
+		return 0, publishErr
+	}
+
+	const markSent = `
+		UPDATE consent_purges
+		   SET sent_at = NOW(),
+		       attempts = attempts + 1,
+		       last_error = NULL
+		 WHERE id = ANY($1)
+	`
+	if _, err := tx.Exec(ctx, markSent, ids); err != nil {
+		return 0, fmt.Errorf("mark consent_purges sent: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return len(purges), nil
+}
+
+// PurgeSentOutbox는 발행이 끝난 지 olderThan 이상 지난 아웃박스 행을 삭제합니다.
+func (r *EventRepository) PurgeSentOutbox(ctx context.Context, olderThan time.Duration) (int64, error) {
+	if r == nil || r.pool == nil {
+		return 0, fmt.Errorf("event repository not initialised")
+	}
+
+	const query = `
+		DELETE FROM ingestion_outbox
+		 WHERE sent_at IS NOT NULL
+		   AND sent_at < $1
+	`
+
+	ct, err := r.pool.Exec(ctx, query, time.Now().UTC().Add(-olderThan))
+	if err != nil {
+		return 0, fmt.Errorf("purge ingestion_outbox: %w", err)
+	}
+	return ct.RowsAffected(), nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PartnerLink는 partner_links 테이블의 사용자별 외부 파트너 연동입니다.
+type PartnerLink struct {
+	UserID    string     `json:"user_id"`
+	Partner   string     `json:"partner"`
+	LinkedAt  time.Time  `json:"linked_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// LinkPartner는 파트너 연동을 기록합니다. 이미 유효한 연동이 있으면 linked_at을 유지하고,
+// 해제된 연동을 다시 만들면 linked_at을 현재 시각으로 새로 설정합니다.
+func (r *EventRepository) LinkPartner(ctx context.Context, userID, partner string) (PartnerLink, error) {
+	if r == nil || r.pool == nil {
+		return PartnerLink{}, fmt.Errorf("event repository not initialised")
+	}
+
+	const query = `
+		INSERT INTO partner_links (
+			user_id,
+			partner
+		) VALUES ($1, $2)
+		ON CONFLICT (user_id, partner) DO UPDATE
+		   SET linked_at = CASE WHEN partner_links.revoked_at IS NULL
+		                        THEN partner_links.linked_at
+		                        ELSE NOW() END,
+		       revoked_at = NULL
+		RETURNING linked_at
+	`
+
+	link := PartnerLink{UserID: userID, Partner: partner}
+	if err := r.pool.QueryRow(ctx, query, userID, partner).Scan(&link.LinkedAt); err != nil {
+		return PartnerLink{}, fmt.Errorf("upsert partner_link: %w", err)
+	}
+	return link, nil
+}
+
+// ListPartnerLinks는 사용자의 파트너 연동 기록(해제 포함)을 반환합니다.
+func (r *EventRepository) ListPartnerLinks(ctx context.Context, userID string) ([]PartnerLink, error) {
+	if r == nil || r.pool == nil {
+		return nil, fmt.Errorf("event repository not initialised")
+	}
+
+	const query = `
+		SELECT user_id,
+		       partner,
+		       linked_at,
+		       revoked_at
+		  FROM partner_links
+		 WHERE user_id = $1
+		 ORDER BY partner
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query partner_links: %w", err)
+	}
+	defer rows.Close()
+
+	links := []PartnerLink{}
+	for rows.Next() {
+		var l PartnerLink
+		if err := rows.Scan(&l.UserID, &l.Partner, &l.LinkedAt, &l.RevokedAt); err != nil {
+			return nil, fmt.Errorf("scan partner_link row: %w", err)
+		}
+		links = append(links, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate partner_links: %w", err)
+	}
+	return links, nil
+}
+
+// UnlinkPartner는 파트너 연동을 해제합니다. 유효한 연동이 없으면 false를 반환합니다.
+func (r *EventRepository) UnlinkPartner(ctx context.Context, userID, partner string) (bool, error) {
+	if r == nil || r.pool == nil {
+		return false, fmt.Errorf("event repository not initialised")
+	}
+
+	const query = `
+		UPDATE partner_links
+		   SET revoked_at = NOW()
+		 WHERE user_id = $1
+		   AND partner = $2
+		   AND revoked_at IS NULL
+	`
+
+	ct, err := r.pool.Exec(ctx, query, userID, partner)
+	if err != nil {
+		return false, fmt.Errorf("revoke partner_link: %w", err)
+	}
+	return ct.RowsAffected() > 0, nil
+}
+
+// LinkedPartnerUsers는 userIDs 중 partner와 연동이 유효한 사용자를 반환합니다. userIDs는 UUID여야 합니다.
+func (r *EventRepository) LinkedPartnerUsers(ctx context.Context, partner string, userIDs []string) (map[string]bool, error) {
+	if r == nil || r.pool == nil {
+		return nil, fmt.Errorf("event repository not initialised")
+	}
+
+	const query = `
+		SELECT user_id
+		  FROM partner_links
+		 WHERE partner = $1
+		   AND user_id = ANY($2::text[]::uuid[])
+		   AND revoked_at IS NULL
+	`
+
+	rows, err := r.pool.Query(ctx, query, partner, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query partner_links: %w", err)
+	}
+	defer rows.Close()
+
+	linked := make(map[string]bool, len(userIDs))
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("scan partner_link row: %w", err)
+		}
+		linked[userID] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate partner_links: %w", err)
+	}
+	return linked, nil
+}
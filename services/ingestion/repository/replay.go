@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ReplayFilter는 재발행할 activity_events의 범위입니다. UserIDs가 비어 있으면 모든 사용자를 대상으로 합니다.
+// Since/Until은 timestamp_start 기준 [Since, Until) 구간이며 0 값은 제한 없음입니다.
+type ReplayFilter struct {
+	UserIDs []string
+	Since   time.Time
+	Until   time.Time
+}
+
+// ReplayCursor는 (timestamp_start, event_id) 순서의 마지막 처리 위치입니다.
+type ReplayCursor struct {
+	StartedAt time.Time
+	EventID   string
+}
+
+// ReplayCheckpoint는 재발행 작업의 재개 지점입니다.
+type ReplayCheckpoint struct {
+	Name        string
+	Params      json.RawMessage
+	Cursor      ReplayCursor
+	Published   int64
+	CompletedAt *time.Time
+}
+
+var (
+	replayMinTime = time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	replayMaxTime = time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// ListEventsForReplay는 after 이후의 이벤트를 (timestamp_start, event_id) 순으로 최대 limit개 반환합니다.
+func (r *EventRepository) ListEventsForReplay(ctx context.Context, filter ReplayFilter, after *ReplayCursor, limit int) ([]Event, error) {
+	if r == nil || r.pool == nil {
+		return nil, fmt.Errorf("event repository not initialised")
+	}
+
+	const query = `
+		SELECT event_id,
+		       user_id,
+		       COALESCE(device_id::text, ''),
+		       source,
+		       timestamp_start,
+		       timestamp_end,
+		       metadata
+		  FROM activity_events
+		 WHERE ($1::text[] IS NULL OR user_id = ANY($1::text[]::uuid[]))
+		   AND timestamp_start >= $2
+		   AND timestamp_start < $3
+		   AND (timestamp_start, event_id) > ($4, $5::uuid)
+		 ORDER BY timestamp_start, event_id
+		 LIMIT $6
+	`
+
+	since, until := filter.Since, filter.Until
+	if since.IsZero() {
+		since = replayMinTime
+	}
+	if until.IsZero() {
+		until = replayMaxTime
+	}
+	cursor := ReplayCursor{StartedAt: replayMinTime, EventID: "00000000-0000-0000-0000-000000000000"}
+	if after != nil {
+		cursor = *after
+	}
+	var userIDs []string
+	if len(filter.UserIDs) > 0 {
+		userIDs = filter.UserIDs
+	}
+
+	rows, err := r.pool.Query(ctx, query, userIDs, since, until, cursor.StartedAt, cursor.EventID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query activity_events for replay: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]Event, 0, limit)
+	for rows.Next() {
+		var (
+			e            Event
+			metadataJSON []byte
+		)
+		if err := rows.Scan(
+			&e.EventID,
+			&e.UserID,
+			&e.DeviceID,
+			&e.Source,
+			&e.TimestampStart,
+			&e.TimestampEnd,
+			&metadataJSON,
+		); err != nil {
+			return nil, fmt.Errorf("scan activity_events row: %w", err)
+		}
+		if len(metadataJSON) > 0 {
+			if err := json.Unmarshal(metadataJSON, &e.Metadata); err != nil {
+				return nil, fmt.Errorf("unmarshal metadata for %s: %w", e.EventID, err)
+			}
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate activity_events: %w", err)
+	}
+	return events, nil
+}
+
+// GetReplayCheckpoint는 이름에 해당하는 재개 지점을 반환합니다. 없으면 nil을 반환합니다.
+func (r *EventRepository) GetReplayCheckpoint(ctx context.Context, name string) (*ReplayCheckpoint, error) {
+	if r == nil || r.pool == nil {
+		return nil, fmt.Errorf("event repository not initialised")
+	}
+
+	const query = `
+		SELECT name,
+		       params,
+		       last_started_at,
+		       last_event_id,
+		       published,
+		       completed_at
+		  FROM replay_checkpoints
+		 WHERE name = $1
+	`
+
+	var cp ReplayCheckpoint
+	if err := r.pool.QueryRow(ctx, query, name).Scan(
+		&cp.Name,
+		&cp.Params,
+		&cp.Cursor.StartedAt,
+		&cp.Cursor.EventID,
+		&cp.Published,
+		&cp.CompletedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select replay_checkpoint: %w", err)
+	}
+	return &cp, nil
+}
+
+// SaveReplayCheckpoint는 재개 지점을 저장합니다. completed가 true이면 완료 시각도 기록합니다.
+func (r *EventRepository) SaveReplayCheckpoint(ctx context.Context, cp ReplayCheckpoint, completed bool) error {
+	if r == nil || r.pool == nil {
+		return fmt.Errorf("event repository not initialised")
+	}
+
+	const query = `
+		INSERT INTO replay_checkpoints (
+			name,
+			params,
+			last_started_at,
+			last_event_id,
+			published,
+			completed_at
+		) VALUES ($1, $2, $3, $4, $5, CASE WHEN $6 THEN NOW() END)
+		ON CONFLICT (name) DO UPDATE
+		   SET params = EXCLUDED.params,
+		       last_started_at = EXCLUDED.last_started_at,
+		       last_event_id = EXCLUDED.last_event_id,
+		       published = EXCLUDED.published,
+		       completed_at = EXCLUDED.completed_at,
+		       updated_at = NOW()
+	`
+
+	if _, err := r.pool.Exec(ctx, query,
+		cp.Name,
+		cp.Params,
+		cp.Cursor.StartedAt,
+		cp.Cursor.EventID,
+		cp.Published,
+		completed,
+	); err != nil {
+		return fmt.Errorf("upsert replay_checkpoint: %w", err)
+	}
+	return nil
+}
+
+// DeleteReplayCheckpoint는 재개 지점을 삭제합니다.
+func (r *EventRepository) DeleteReplayCheckpoint(ctx context.Context, name string) error {
+	if r == nil || r.pool == nil {
+		return fmt.Errorf("event repository not initialised")
+	}
+	if _, err := r.pool.Exec(ctx, `DELETE FROM replay_checkpoints WHERE name = $1`, name); err != nil {
+		return fmt.Errorf("delete replay_checkpoint: %w", err)
+	}
+	return nil
+}
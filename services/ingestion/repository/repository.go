@@ -3,20 +3,34 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrIdempotencyConflict는 같은 멱등 키(또는 event_id)로 다른 페이로드가 들어왔을 때 반환됩니다.
+var ErrIdempotencyConflict = errors.New("idempotency key reused with a different payload")
+
 // Event는 activity_events 테이블에 삽입될 데이터 모델입니다.
 type Event struct {
-	EventID       string
-	UserID        string
-	Source        string
+	EventID        string
+	UserID         string
+	DeviceID       string
+	Source         string
 	TimestampStart time.Time
 	TimestampEnd   time.Time
 	Metadata       map[string]any
+	// Message는 아웃박스를 거쳐 Kafka로 발행될 메시지 본문입니다.
+	Message []byte
+}
+
+// SaveResult는 멱등 저장 결과입니다. Replayed가 true이면 이미 처리된 요청입니다.
+type SaveResult struct {
+	EventID  string
+	Replayed bool
 }
 
 type EventRepository struct {
@@ -27,49 +41,197 @@ func NewEventRepository(pool *pgxpool.Pool) *EventRepository {
 	return &EventRepository{pool: pool}
 }
 
-// Save는 activity_events 테이블에 이벤트를 저장합니다.
+// Save는 activity_events 테이블에 이벤트를 저장하고 같은 트랜잭션에서 아웃박스에 발행 메시지를 적재합니다.
 func (r *EventRepository) Save(ctx context.Context, event Event) error {
 	if r == nil || r.pool == nil {
 		return fmt.Errorf("event repository not initialised")
 	}
 
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // nolint:errcheck
+
+	if _, err := insertEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// BatchItem은 SaveBatch에 전달되는 단일 이벤트입니다. Key가 비어 있으면 멱등 키 없이 저장합니다.
+type BatchItem struct {
+	Key         string
+	RequestHash string
+	Event       Event
+}
+
+// BatchResult는 SaveBatch의 항목별 결과입니다. Conflict가 true이면 해당 항목은 저장되지 않았습니다.
+type BatchResult struct {
+	SaveResult
+	Conflict bool
+}
+
+// SaveBatch는 여러 이벤트를 하나의 트랜잭션으로 저장하고 입력 순서대로 항목별 결과를 반환합니다.
+// 멱등 키 충돌은 항목 단위로 보고하며, 그 밖의 데이터베이스 오류는 배치 전체를 롤백합니다.
+func (r *EventRepository) SaveBatch(ctx context.Context, items []BatchItem) ([]BatchResult, error) {
+	if r == nil || r.pool == nil {
+		return nil, fmt.Errorf("event repository not initialised")
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // nolint:errcheck
+
+	results := make([]BatchResult, len(items))
+	for i, item := range items {
+		if item.Key == "" {
+			inserted, err := insertEvent(ctx, tx, item.Event)
+			if err != nil {
+				return nil, fmt.Errorf("batch item %d: %w", i, err)
+			}
+			results[i] = BatchResult{
+				SaveResult: SaveResult{EventID: item.Event.EventID, Replayed: !inserted},
+			}
+			continue
+		}
+
+		// 충돌 시 이미 예약된 키를 되돌리기 위해 항목마다 세이브포인트를 사용한다.
+		sp, err := tx.Begin(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("batch item %d: begin savepoint: %w", i, err)
+		}
+		result, err := saveIdempotentTx(ctx, sp, item.Key, item.RequestHash, item.Event)
+		switch {
+		case errors.Is(err, ErrIdempotencyConflict):
+			if err := sp.Rollback(ctx); err != nil {
+				return nil, fmt.Errorf("batch item %d: rollback savepoint: %w", i, err)
+			}
+			results[i] = BatchResult{Conflict: true}
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("batch item %d: %w", i, err)
+		}
+		if err := sp.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("batch item %d: release savepoint: %w", i, err)
+		}
+		results[i] = BatchResult{SaveResult: result}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return results, nil
+}
+
+// Ping은 데이터베이스 연결 상태를 확인합니다.
+func (r *EventRepository) Ping(ctx context.Context) error {
+	if r == nil || r.pool == nil {
+		return fmt.Errorf("event repository not initialised")
+	}
+	return r.pool.Ping(ctx)
+}
+
+// saveIdempotentTx는 트랜잭션 안에서 멱등 키를 예약하고 이벤트를 저장합니다.
+// 키 예약과 이벤트 저장은 ON CONFLICT DO NOTHING을 사용하므로 충돌이 트랜잭션을 중단시키지 않습니다.
+func saveIdempotentTx(ctx context.Context, tx pgx.Tx, key, requestHash string, event Event) (SaveResult, error) {
+	const reserve = `
+		INSERT INTO ingestion_idempotency_keys (
+			user_id,
+			idempotency_key,
+			request_hash,
+			event_id
+		) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, idempotency_key) DO NOTHING
+	`
+
+	ct, err := tx.Exec(ctx, reserve, event.UserID, key, requestHash, event.EventID)
+	if err != nil {
+		return SaveResult{}, fmt.Errorf("reserve idempotency key: %w", err)
+	}
+
+	if ct.RowsAffected() == 0 {
+		const lookup = `
+			SELECT request_hash,
+			       event_id
+			  FROM ingestion_idempotency_keys
+			 WHERE user_id = $1
+			   AND idempotency_key = $2
+		`
+
+		var (
+			storedHash    string
+			storedEventID string
+		)
+		if err := tx.QueryRow(ctx, lookup, event.UserID, key).Scan(&storedHash, &storedEventID); err != nil {
+			return SaveResult{}, fmt.Errorf("lookup idempotency key: %w", err)
+		}
+		if storedHash != requestHash {
+			return SaveResult{}, ErrIdempotencyConflict
+		}
+		return SaveResult{EventID: storedEventID, Replayed: true}, nil
+	}
+
+	inserted, err := insertEvent(ctx, tx, event)
+	if err != nil {
+		return SaveResult{}, err
+	}
+	if !inserted {
+		// 다른 멱등 키가 같은 event_id를 이미 사용한 경우
+		return SaveResult{}, ErrIdempotencyConflict
+	}
+
+	return SaveResult{EventID: event.EventID}, nil
+}
+
+// insertEvent는 이벤트를 저장하고, 새로 저장된 경우에만 아웃박스 메시지를 적재합니다.
+func insertEvent(ctx context.Context, tx pgx.Tx, event Event) (bool, error) {
 	metadataJSON, err := json.Marshal(event.Metadata)
 	if err != nil {
-		return fmt.Errorf("marshal metadata: %w", err)
+		return false, fmt.Errorf("marshal metadata: %w", err)
 	}
 
 	const query = `
 		INSERT INTO activity_events (
 			event_id,
 			user_id,
+			device_id,
 			source,
 			timestamp_start,
 			timestamp_end,
 			metadata
-		) VALUES ($1, $2, $3, $4, $5, $6)
+		) VALUES ($1, $2, NULLIF($3, '')::uuid, $4, $5, $6, $7)
+		ON CONFLICT (event_id) DO NOTHING
 	`
 
-	_, err = r.pool.Exec(
+	ct, err := tx.Exec(
 		ctx,
 		query,
 		event.EventID,
 		event.UserID,
+		event.DeviceID,
 		event.Source,
 		event.TimestampStart,
 		event.TimestampEnd,
 		metadataJSON,
 	)
 	if err != nil {
-		return fmt.Errorf("insert activity_event: %w", err)
+		return false, fmt.Errorf("insert activity_event: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return false, nil
 	}
 
-	return nil
-}
-
-// Ping은 데이터베이스 연결 상태를 확인합니다.
-func (r *EventRepository) Ping(ctx context.Context) error {
-	if r == nil || r.pool == nil {
-		return fmt.Errorf("event repository not initialised")
+	if err := enqueueOutbox(ctx, tx, event); err != nil {
+		return false, err
 	}
-	return r.pool.Ping(ctx)
+
+	return true, nil
 }
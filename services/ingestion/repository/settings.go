@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+// UserTimezones는 사용자별 user_settings.timezone을 반환합니다. 설정 행이 없는 사용자는 결과에 포함되지 않습니다.
+func (r *EventRepository) UserTimezones(ctx context.Context, userIDs []string) (map[string]string, error) {
+	if r == nil || r.pool == nil {
+		return nil, fmt.Errorf("event repository not initialised")
+	}
+
+	const query = `
+		SELECT user_id,
+		       timezone
+		  FROM user_settings
+		 WHERE user_id = ANY($1::text[]::uuid[])
+	`
+
+	rows, err := r.pool.Query(ctx, query, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query user timezones: %w", err)
+	}
+	defer rows.Close()
+
+	timezones := make(map[string]string, len(userIDs))
+	for rows.Next() {
+		var userID, timezone string
+		if err := rows.Scan(&userID, &timezone); err != nil {
+			return nil, fmt.Errorf("scan user timezone row: %w", err)
+		}
+		timezones[userID] = timezone
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate user timezones: %w", err)
+	}
+	return timezones, nil
+}
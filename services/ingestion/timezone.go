@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// timeContextKey는 서버가 기록하는 시각 해석 정보의 메타데이터 키입니다. 클라이언트가 보낸 값은 덮어쓴다.
+const timeContextKey = "time_context"
+
+// floatingLayouts는 오프셋 없이 들어오는 시각 형식입니다. 사용자 시간대의 벽시계 시각으로 해석합니다.
+var floatingLayouts = []string{
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04",
+}
+
+// locations는 time.LoadLocation 결과를 시간대 이름별로 캐시합니다.
+var locations sync.Map
+
+// UnmarshalJSON은 started_at/ended_at에 오프셋이 없는 시각도 받아들이고, 해당 여부를 기록합니다.
+func (e *activityEvent) UnmarshalJSON(data []byte) error {
+	type plain activityEvent
+	aux := struct {
+		*plain
+		StartedAt string `json:"started_at"`
+		EndedAt   string `json:"ended_at"`
+	}{plain: (*plain)(e)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	var err error
+	if e.StartedAt, e.startFloating, err = parseEventTime(aux.StartedAt); err != nil {
+		return fmt.Errorf("started_at: %w", err)
+	}
+	if e.EndedAt, e.endFloating, err = parseEventTime(aux.EndedAt); err != nil {
+		return fmt.Errorf("ended_at: %w", err)
+	}
+	return nil
+}
+
+// parseEventTime은 RFC3339 시각을 해석하고, 오프셋이 없으면 UTC 벽시계 시각과 floating=true를 반환합니다.
+// 빈 문자열은 zero time으로 두어 검증 단계에서 필수 항목 오류가 나도록 한다.
+func parseEventTime(value string) (t time.Time, floating bool, err error) {
+	if value == "" {
+		return time.Time{}, false, nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, false, nil
+	}
+	for _, layout := range floatingLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("invalid timestamp %q", value)
+}
+
+// loadLocation은 캐시된 *time.Location을 반환합니다.
+func loadLocation(name string) (*time.Location, error) {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// timezoneSet은 한 번의 수집 호출 동안 사용하는 사용자별 시간대입니다.
+type timezoneSet struct {
+	users    map[string]*time.Location
+	fallback *time.Location
+}
+
+// loadTimezones는 수집 항목에 등장하는 사용자의 user_settings.timezone을 한 번에 조회합니다.
+// 설정이 없거나 알 수 없는 시간대인 사용자는 INGESTION_DEFAULT_TIMEZONE을 사용합니다.
+func (s *server) loadTimezones(ctx context.Context, items []ingestItem) (timezoneSet, error) {
+	fallback, err := loadLocation(s.cfg.Ingestion.DefaultTimezone)
+	if err != nil {
+		return timezoneSet{}, fmt.Errorf("load default timezone: %w", err)
+	}
+	set := timezoneSet{users: map[string]*time.Location{}, fallback: fallback}
+	if s.repo == nil {
+		return set, nil
+	}
+
+	userIDs := ingestUserIDs(items)
+	if len(userIDs) == 0 {
+		return set, nil
+	}
+	names, err := s.repo.UserTimezones(ctx, userIDs)
+	if err != nil {
+		return timezoneSet{}, fmt.Errorf("load timezones: %w", err)
+	}
+	for userID, name := range names {
+		loc, err := loadLocation(name)
+		if err != nil {
+			s.logger.Warnw("unknown user timezone, using default", "user_id", userID, "timezone", name)
+			continue
+		}
+		set.users[userID] = loc
+	}
+	return set, nil
+}
+
+func (z timezoneSet) location(userID string) *time.Location {
+	if loc, ok := z.users[userID]; ok {
+		return loc
+	}
+	return z.fallback
+}
+
+// normalise는 오프셋 없는 시각을 사용자 시간대로 해석하고 started_at/ended_at을 UTC로 바꿉니다.
+// 반환되는 맵은 원래 오프셋과 사용자 시간대 기준 날짜를 담으며, 검증과 요청 해시 계산 뒤 annotateTime으로 메타데이터에 기록한다.
+// 서머타임 전환으로 존재하지 않거나 두 번 나타나는 벽시계 시각은 time.Date 규칙에 따라 한쪽 오프셋으로 정해진다.
+func (z timezoneSet) normalise(payload *activityEvent, now time.Time, skewThreshold time.Duration) map[string]interface{} {
+	if payload.StartedAt.IsZero() || payload.EndedAt.IsZero() {
+		return nil
+	}
+	loc := z.location(payload.UserID)
+	if payload.startFloating {
+		payload.StartedAt = inLocation(payload.StartedAt, loc)
+	}
+	if payload.endFloating {
+		payload.EndedAt = inLocation(payload.EndedAt, loc)
+	}
+
+	_, offset := payload.StartedAt.Zone()
+	timeContext := map[string]interface{}{
+		"timezone":   loc.String(),
+		"utc_offset": formatOffset(offset),
+		"local_date": payload.StartedAt.In(loc).Format("2006-01-02"),
+	}
+	if payload.startFloating || payload.endFloating {
+		timeContext["offset_inferred"] = true
+	}
+	// 미래 시각만 시계 오차로 본다. 과거 시각은 백필이나 지연 업로드일 수 있다.
+	if skew := payload.EndedAt.Sub(now); skewThreshold > 0 && skew > skewThreshold {
+		timeContext["clock_skew"] = true
+		timeContext["clock_skew_seconds"] = int64(math.Round(skew.Seconds()))
+	}
+
+	payload.StartedAt = payload.StartedAt.UTC()
+	payload.EndedAt = payload.EndedAt.UTC()
+	payload.startFloating, payload.endFloating = false, false
+	return timeContext
+}
+
+// annotateTime은 normalise가 만든 시각 해석 정보를 메타데이터에 기록합니다. 원본 맵은 수정하지 않는다.
+func annotateTime(metadata, timeContext map[string]interface{}) map[string]interface{} {
+	if timeContext == nil {
+		return metadata
+	}
+	annotated := make(map[string]interface{}, len(metadata)+1)
+	for k, v := range metadata {
+		annotated[k] = v
+	}
+	annotated[timeContextKey] = timeContext
+	return annotated
+}
+
+func inLocation(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// formatOffset은 초 단위 UTC 오프셋을 `+09:00` 형식으로 변환합니다.
+func formatOffset(seconds int) string {
+	sign := '+'
+	if seconds < 0 {
+		sign = '-'
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, seconds/3600, seconds%3600/60)
+}
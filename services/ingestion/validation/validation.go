@@ -0,0 +1,251 @@
+package validation
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// 소스 종류. 여러 소스 이름이 같은 종류의 메타데이터 스키마를 공유한다.
+const (
+	KindScreenTime = "screen_time"
+	KindLocation   = "location"
+	KindCalendar   = "calendar"
+	KindHealth     = "health"
+)
+
+//go:embed schemas/*.json
+var schemaFS embed.FS
+
+// defaultSources는 수집을 허용하는 소스 이름과 해당 스키마 종류의 매핑입니다.
+var defaultSources = map[string]string{
+	"screen_time":         KindScreenTime,
+	"ios_screen_time":     KindScreenTime,
+	"android_usage_stats": KindScreenTime,
+	"location":            KindLocation,
+	"ios_location":        KindLocation,
+	"android_location":    KindLocation,
+	"calendar":            KindCalendar,
+	"health":              KindHealth,
+	"apple_health":        KindHealth,
+	"google_fit":          KindHealth,
+}
+
+// Event는 검증 대상 이벤트입니다.
+type Event struct {
+	EventID   string
+	UserID    string
+	Source    string
+	StartedAt time.Time
+	EndedAt   time.Time
+	Metadata  map[string]any
+}
+
+// FieldError는 필드 단위 검증 오류입니다. Field는 `metadata.latitude`처럼 점으로 구분된 경로입니다.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error는 하나 이상의 FieldError를 담는 검증 오류입니다.
+type Error struct {
+	Fields []FieldError
+}
+
+func (e *Error) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, f.Field+": "+f.Message)
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (e *Error) add(field, format string, args ...any) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Registry는 알려진 소스와 소스 종류별 메타데이터 JSON Schema를 보관합니다.
+type Registry struct {
+	sources map[string]string
+	schemas map[string]*jsonschema.Schema
+}
+
+// NewRegistry는 내장된 스키마를 컴파일해 기본 소스가 등록된 Registry를 생성합니다.
+func NewRegistry() (*Registry, error) {
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+
+	kinds := []string{KindScreenTime, KindLocation, KindCalendar, KindHealth}
+	for _, kind := range kinds {
+		raw, err := schemaFS.ReadFile("schemas/" + kind + ".json")
+		if err != nil {
+			return nil, fmt.Errorf("read %s schema: %w", kind, err)
+		}
+		if err := compiler.AddResource(schemaURL(kind), bytes.NewReader(raw)); err != nil {
+			return nil, fmt.Errorf("add %s schema: %w", kind, err)
+		}
+	}
+
+	schemas := make(map[string]*jsonschema.Schema, len(kinds))
+	for _, kind := range kinds {
+		schema, err := compiler.Compile(schemaURL(kind))
+		if err != nil {
+			return nil, fmt.Errorf("compile %s schema: %w", kind, err)
+		}
+		schemas[kind] = schema
+	}
+
+	sources := make(map[string]string, len(defaultSources))
+	for name, kind := range defaultSources {
+		sources[name] = kind
+	}
+
+	return &Registry{sources: sources, schemas: schemas}, nil
+}
+
+// Kind는 소스 이름에 해당하는 스키마 종류를 반환합니다.
+func (r *Registry) Kind(source string) (string, bool) {
+	kind, ok := r.sources[source]
+	return kind, ok
+}
+
+// Sources는 등록된 소스 이름을 정렬해 반환합니다.
+func (r *Registry) Sources() []string {
+	names := make([]string, 0, len(r.sources))
+	for name := range r.sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsKind는 name이 소스 종류 이름인지 확인합니다.
+func (r *Registry) IsKind(name string) bool {
+	_, ok := r.schemas[name]
+	return ok
+}
+
+// SourcesOfKind는 해당 종류에 속한 소스 이름을 정렬해 반환합니다.
+func (r *Registry) SourcesOfKind(kind string) []string {
+	var names []string
+	for name, k := range r.sources {
+		if k == kind {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate는 공통 필드와 소스별 메타데이터 스키마를 검사하고, 위반 사항이 있으면 *Error를 반환합니다.
+func (r *Registry) Validate(evt Event) error {
+	verr := &Error{}
+
+	if evt.EventID != "" {
+		if _, err := uuid.Parse(evt.EventID); err != nil {
+			verr.add("event_id", "must be a UUID")
+		}
+	}
+	if evt.UserID == "" {
+		verr.add("user_id", "is required")
+	} else if _, err := uuid.Parse(evt.UserID); err != nil {
+		verr.add("user_id", "must be a UUID")
+	}
+
+	if evt.StartedAt.IsZero() {
+		verr.add("started_at", "is required")
+	}
+	if evt.EndedAt.IsZero() {
+		verr.add("ended_at", "is required")
+	}
+	if !evt.StartedAt.IsZero() && !evt.EndedAt.IsZero() && evt.EndedAt.Before(evt.StartedAt) {
+		verr.add("ended_at", "must not be before started_at")
+	}
+
+	kind, known := r.Kind(evt.Source)
+	switch {
+	case evt.Source == "":
+		verr.add("source", "is required")
+	case !known:
+		verr.add("source", "unknown source %q", evt.Source)
+	default:
+		if err := r.validateMetadata(kind, evt.Metadata); err != nil {
+			verr.Fields = append(verr.Fields, metadataErrors(err)...)
+		}
+	}
+
+	if len(verr.Fields) > 0 {
+		return verr
+	}
+	return nil
+}
+
+func (r *Registry) validateMetadata(kind string, metadata map[string]any) error {
+	schema, ok := r.schemas[kind]
+	if !ok {
+		return nil
+	}
+
+	// 스키마 검증기는 encoding/json이 만든 타입만 다루므로 한 번 왕복 직렬화한다.
+	raw, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("marshal metadata: %w", err)
+	}
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return fmt.Errorf("unmarshal metadata: %w", err)
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+
+	return schema.Validate(doc)
+}
+
+// metadataErrors는 스키마 검증 오류를 말단 원인 기준의 FieldError 목록으로 펼칩니다.
+func metadataErrors(err error) []FieldError {
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return []FieldError{{Field: "metadata", Message: err.Error()}}
+	}
+
+	var fields []FieldError
+	var walk func(*jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			fields = append(fields, FieldError{Field: fieldPath(e.InstanceLocation), Message: e.Message})
+			return
+		}
+		for _, cause := range e.Causes {
+			walk(cause)
+		}
+	}
+	walk(ve)
+	return fields
+}
+
+// fieldPath는 JSON Pointer(`/a/b`)를 `metadata.a.b` 형태로 변환합니다.
+func fieldPath(pointer string) string {
+	pointer = strings.TrimPrefix(pointer, "/")
+	if pointer == "" {
+		return "metadata"
+	}
+	segments := strings.Split(pointer, "/")
+	for i, seg := range segments {
+		seg = strings.ReplaceAll(seg, "~1", "/")
+		segments[i] = strings.ReplaceAll(seg, "~0", "~")
+	}
+	return "metadata." + strings.Join(segments, ".")
+}
+
+func schemaURL(kind string) string {
+	return "https://schemas.daylog.app/activity/" + kind + ".json"
+}
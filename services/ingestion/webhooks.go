@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"daylog/services/ingestion/importer"
+	"daylog/services/ingestion/partner"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// 파트너 이벤트 거절 사유
+const (
+	reasonPartnerIDRequired = "partner event id is required"
+	reasonPartnerNotLinked  = "user has not linked this partner"
+)
+
+// handlePartnerWebhook은 외부 연동 파트너가 서버 간으로 보내는 서명된 웹훅을 수집합니다.
+// 서명 검증 후 파트너별 변환기로 이벤트를 만들고, 결과는 배치 수집과 같은 형식으로 응답합니다.
+// 파트너 레코드 ID가 없는 이벤트와 그 파트너를 연동하지 않은 사용자의 이벤트는 항목별로 거절합니다.
+func (s *server) handlePartnerWebhook(w http.ResponseWriter, r *http.Request) {
+	// 연동 여부와 재전송 중복을 확인할 수 없으므로 Postgres 없이는 받지 않는다.
+	if s.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "partner webhooks require postgres"})
+		return
+	}
+
+	// 파트너 존재 여부를 노출하지 않도록 미등록 파트너도 서명 실패와 같은 응답을 준다.
+	p, ok := s.partners.Lookup(mux.Vars(r)["partner"])
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "signature verification failed"})
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxBatchBodyBytes)
+	defer body.Close()
+
+	payload, err := io.ReadAll(body)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": "payload too large"})
+			return
+		}
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "failed to read request"})
+		return
+	}
+
+	sig := r.Header.Get(partner.SignatureHeader)
+	if err := partner.Verify(payload, sig, p.Secrets, s.cfg.Ingestion.PartnerSignatureTolerance, time.Now()); err != nil {
+		s.logger.Warnw("partner signature verification failed", "partner", p.Name, "error", err)
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "signature verification failed"})
+		return
+	}
+
+	events, err := p.Map(payload)
+	if err != nil {
+		s.logger.Warnw("failed to map partner payload", "partner", p.Name, "error", err)
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+	if len(events) > maxBatchItems {
+		writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{
+			"error": fmt.Sprintf("payload exceeds %d events", maxBatchItems),
+		})
+		return
+	}
+
+	linked, err := s.repo.LinkedPartnerUsers(r.Context(), p.Name, partnerUserIDs(events))
+	if err != nil {
+		s.logger.Errorw("failed to load partner links", "partner", p.Name, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to verify partner links"})
+		return
+	}
+
+	rejected := make([]string, len(events))
+	items := make([]ingestItem, 0, len(events))
+	indexes := make([]int, 0, len(events))
+	for i, e := range events {
+		switch {
+		case e.ExternalID == "":
+			// ID가 없으면 멱등 키를 만들 수 없어, 허용 구간 안의 재전송이 매번 새 이벤트로 저장된다.
+			rejected[i] = reasonPartnerIDRequired
+		case !linked[e.UserID]:
+			rejected[i] = reasonPartnerNotLinked
+		default:
+			items = append(items, partnerItem(p.Name, e))
+			indexes = append(indexes, i)
+		}
+	}
+
+	ingested, err := s.ingest(r.Context(), items)
+	if err != nil {
+		s.logger.Errorw("failed to persist partner events", "partner", p.Name, "error", err, "count", len(items))
+		writeIngestError(w, err)
+		return
+	}
+
+	resp := batchResponse{Results: make([]batchItemResult, 0, len(events))}
+	next := 0
+	for i := range events {
+		if next < len(indexes) && indexes[next] == i {
+			resp.add(i, ingested[next])
+			next++
+			continue
+		}
+		resp.add(i, ingestResult{Status: ingestStatusRejected, Reason: rejected[i]})
+	}
+	writeJSON(w, http.StatusAccepted, resp)
+}
+
+// partnerUserIDs는 파트너 이벤트에 등장하는 UUID 형식의 사용자 ID를 중복 없이 반환합니다.
+func partnerUserIDs(events []partner.Event) []string {
+	seen := make(map[string]bool)
+	userIDs := make([]string, 0, 1)
+	for _, e := range events {
+		if seen[e.UserID] {
+			continue
+		}
+		seen[e.UserID] = true
+		if _, err := uuid.Parse(e.UserID); err == nil {
+			userIDs = append(userIDs, e.UserID)
+		}
+	}
+	return userIDs
+}
+
+// partnerItem은 파트너 이벤트를 수집 항목으로 변환합니다. e.ExternalID는 비어 있지 않아야 합니다.
+// 파트너 레코드 ID로 만든 결정적 event_id를 멱등 키로 사용하므로, 허용 구간 안에서 재전송된 웹훅도 중복 저장되지 않습니다.
+func partnerItem(name string, e partner.Event) ingestItem {
+	metadata := e.Metadata
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	metadata["partner"] = name
+
+	payload := activityEvent{
+		EventID:   importer.StableEventID(e.UserID, "partner", name, e.ExternalID),
+		UserID:    e.UserID,
+		Source:    e.Source,
+		StartedAt: e.StartedAt,
+		EndedAt:   e.EndedAt,
+		Metadata:  metadata,
+	}
+	return ingestItem{Payload: payload, Key: payload.EventID}
+}
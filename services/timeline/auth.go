@@ -0,0 +1,37 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// userIDHeader는 게이트웨이가 인증한 사용자 ID를 내부 리스너의 사용자 API에 전달하는 헤더입니다.
+const userIDHeader = "X-User-Id"
+
+// requireUser는 내부 리스너의 사용자 API에서 게이트웨이가 전달한 X-User-Id가 경로의 userId와 같은지 확인합니다.
+// 내부 리스너는 게이트웨이와 운영자만 접근할 수 있으므로 헤더를 신뢰하며, 사용자는 자신의 자원만 관리할 수 있다.
+func requireUser(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		callerID, ok := authenticatedUser(w, r)
+		if !ok {
+			return
+		}
+		if callerID != mux.Vars(r)["userId"] {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "userId does not match authenticated user"})
+			return
+		}
+		next(w, r)
+	}
+}
+
+// authenticatedUser는 게이트웨이가 전달한 X-User-Id를 반환합니다. 없으면 401로 응답하고 false를 반환한다.
+func authenticatedUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	callerID := strings.TrimSpace(r.Header.Get(userIDHeader))
+	if callerID == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "user authentication required"})
+		return "", false
+	}
+	return callerID, true
+}
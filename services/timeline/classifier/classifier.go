@@ -0,0 +1,196 @@
+// Package classifier는 활동 분류 서비스(ml-services/activity-classifier)의 /v1/classify를 호출하는 클라이언트입니다.
+// 요청마다 시간 제한을 두고, 동시 요청 수를 제한하며, 연속 실패가 쌓이면 서킷을 열어 분류 서비스 장애가
+// 타임라인 소비를 늦추지 않도록 한다. 호출자는 오류가 나면 규칙 기반 카테고리로 대체해야 한다.
+package classifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"daylog/services/timeline/repository"
+)
+
+// ErrCircuitOpen은 연속 실패로 서킷이 열려 분류 서비스를 호출하지 않았을 때 반환됩니다.
+var ErrCircuitOpen = errors.New("classifier circuit open")
+
+// Config는 분류 클라이언트 설정입니다.
+type Config struct {
+	// URL은 분류 서비스 주소입니다(예: http://activity-classifier:8000).
+	URL string
+	// Timeout은 요청 하나의 시간 제한입니다.
+	Timeout time.Duration
+	// Concurrency는 동시에 보낼 수 있는 최대 요청 수입니다.
+	Concurrency int
+	// FailureThreshold는 서킷을 여는 연속 실패 수입니다.
+	FailureThreshold int
+	// Cooldown은 서킷이 열린 뒤 다시 시험 요청을 보내기까지의 시간입니다.
+	Cooldown time.Duration
+}
+
+// Result는 분류 결과입니다.
+type Result struct {
+	Category     string   `json:"category"`
+	Confidence   float64  `json:"confidence"`
+	Rationale    []string `json:"rationale"`
+	ModelVersion string   `json:"model_version"`
+}
+
+// Client는 분류 서비스 클라이언트입니다.
+type Client struct {
+	endpoint string
+	timeout  time.Duration
+	http     *http.Client
+	slots    chan struct{}
+	breaker  *breaker
+}
+
+// New는 Client를 생성합니다.
+func New(cfg Config) (*Client, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("classifier url is required")
+	}
+	if cfg.Timeout <= 0 || cfg.Concurrency <= 0 || cfg.FailureThreshold <= 0 || cfg.Cooldown <= 0 {
+		return nil, errors.New("classifier timeout, concurrency, failure threshold and cooldown must be positive")
+	}
+	return &Client{
+		endpoint: strings.TrimRight(cfg.URL, "/") + "/v1/classify",
+		timeout:  cfg.Timeout,
+		http:     &http.Client{},
+		slots:    make(chan struct{}, cfg.Concurrency),
+		breaker:  &breaker{threshold: cfg.FailureThreshold, cooldown: cfg.Cooldown},
+	}, nil
+}
+
+// classifyRequest는 /v1/classify 요청 본문입니다. 블록은 대표 이벤트의 소스·메타데이터와 병합된 구간으로 보낸다.
+type classifyRequest struct {
+	UserID    string         `json:"user_id"`
+	Source    string         `json:"source"`
+	StartedAt time.Time      `json:"started_at"`
+	EndedAt   time.Time      `json:"ended_at"`
+	Metadata  map[string]any `json:"metadata"`
+}
+
+type classifyResponse struct {
+	Status string `json:"status"`
+	Result Result `json:"result"`
+}
+
+// Classify는 entry(병합된 블록)를 분류합니다.
+// 동시 요청 수가 가득 차면 빈 자리가 날 때까지 기다리며, 기다리는 시간도 Timeout에 포함된다.
+func (c *Client) Classify(ctx context.Context, entry repository.Entry) (Result, error) {
+	if !c.breaker.allow(time.Now()) {
+		return Result{}, ErrCircuitOpen
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	select {
+	case c.slots <- struct{}{}:
+		defer func() { <-c.slots }()
+	case <-ctx.Done():
+		// 분류 서비스 장애가 아니라 대기열이 밀린 것이므로 서킷에 반영하지 않는다.
+		c.breaker.release()
+		return Result{}, fmt.Errorf("wait for classifier slot: %w", ctx.Err())
+	}
+
+	result, err := c.call(ctx, entry)
+	c.breaker.record(err == nil, time.Now())
+	return result, err
+}
+
+func (c *Client) call(ctx context.Context, entry repository.Entry) (Result, error) {
+	metadata := entry.Metadata
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	body, err := json.Marshal(classifyRequest{
+		UserID:    entry.UserID,
+		Source:    entry.Source,
+		StartedAt: entry.StartedAt,
+		EndedAt:   entry.EndedAt,
+		Metadata:  metadata,
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("marshal classify request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return Result{}, fmt.Errorf("build classify request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("classify: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return Result{}, fmt.Errorf("classify: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var out classifyResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&out); err != nil {
+		return Result{}, fmt.Errorf("decode classify response: %w", err)
+	}
+	if out.Result.Category == "" {
+		return Result{}, errors.New("classify: empty category")
+	}
+	return out.Result, nil
+}
+
+// breaker는 연속 실패 수 기반 서킷 브레이커입니다.
+// 닫힘 상태에서 실패가 threshold번 이어지면 열리고, cooldown이 지나면 시험 요청 하나만 통과시킨다(반열림).
+// 시험 요청이 성공하면 닫히고, 실패하면 다시 cooldown 동안 열린다.
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func (b *breaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return true
+	}
+	if now.Before(b.openUntil) || b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// release는 allow로 받은 시험 요청 기회를 결과 없이 반납합니다.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *breaker) record(ok bool, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if ok {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = now.Add(b.cooldown)
+	}
+}
@@ -0,0 +1,405 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"daylog/services/common/fieldcrypt"
+	"daylog/services/common/messaging"
+	"daylog/services/timeline/repository"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// consumerPool은 activity.raw를 여러 작업자로 나눠 처리하는 소비자입니다.
+// 메시지는 키(user_id)의 해시로 작업자를 고르므로 같은 사용자의 이벤트는 파티션과 관계없이 도착 순서대로 처리된다.
+// 오프셋은 파티션마다 앞선 메시지가 모두 끝난 위치까지만 커밋하므로 at-least-once이며, 다시 처리해도 결과는 같다.
+type consumerPool struct {
+	logger         *zap.SugaredLogger
+	consumer       *messaging.Consumer
+	crypt          *fieldcrypt.Encryptor
+	processor      *eventProcessor
+	deadLetters    *messaging.Producer
+	policy         retryPolicy
+	workers        int
+	batchSize      int
+	commitInterval time.Duration
+	drainTimeout   time.Duration
+	offsets        *offsetTracker
+}
+
+// pendingEvent는 디코딩을 마치고 저장을 기다리는 이벤트입니다.
+type pendingEvent struct {
+	msg   *trackedMessage
+	entry repository.Entry
+}
+
+// Run은 ctx가 취소될 때까지 메시지를 가져와 처리합니다.
+// 취소되면 더 가져오지 않고, 이미 가져온 메시지를 drainTimeout 동안 마저 처리한 뒤 끝난 위치까지 커밋하고 반환한다.
+func (c *consumerPool) Run(ctx context.Context) {
+	c.logger.Infow("starting timeline consumer", "workers", c.workers, "batch_size", c.batchSize)
+
+	workCtx, cancelWork := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelWork()
+	stopDrainTimer := context.AfterFunc(ctx, func() {
+		time.AfterFunc(c.drainTimeout, cancelWork)
+	})
+	defer stopDrainTimer()
+
+	queues := make([]chan *trackedMessage, c.workers)
+	var workers sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan *trackedMessage, c.batchSize)
+		workers.Add(1)
+		go func(queue <-chan *trackedMessage) {
+			defer workers.Done()
+			c.work(workCtx, queue)
+		}(queues[i])
+	}
+
+	stopCommit := make(chan struct{})
+	commitDone := make(chan struct{})
+	go func() {
+		defer close(commitDone)
+		ticker := time.NewTicker(c.commitInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCommit:
+				return
+			case <-ticker.C:
+				c.commit(workCtx)
+			}
+		}
+	}()
+
+	c.dispatch(ctx, queues)
+
+	for _, queue := range queues {
+		close(queue)
+	}
+	workers.Wait()
+	close(stopCommit)
+	<-commitDone
+
+	finalCtx, cancelFinal := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	c.commit(finalCtx)
+	cancelFinal()
+	c.logger.Infow("timeline consumer stopped")
+}
+
+// dispatch는 가져온 메시지를 키에 해당하는 작업자 큐에 넣습니다. 큐가 가득 차면 가져오기를 멈추고 기다린다.
+func (c *consumerPool) dispatch(ctx context.Context, queues []chan *trackedMessage) {
+	for {
+		msg, err := c.consumer.Fetch(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.logger.Errorw("failed to fetch kafka message", "error", err)
+			if !sleepContext(ctx, time.Second) {
+				return
+			}
+			continue
+		}
+
+		tracked := c.offsets.track(msg)
+		select {
+		case queues[route(msg, len(queues))] <- tracked:
+		case <-ctx.Done():
+			// 큐에 넣지 못한 메시지는 끝나지 않았으므로 그 뒤 오프셋도 커밋되지 않는다.
+			return
+		}
+	}
+}
+
+// route는 msg를 처리할 작업자 번호입니다. 키가 없으면 파티션으로 고른다.
+func route(msg kafka.Message, workers int) int {
+	if len(msg.Key) == 0 {
+		return msg.Partition % workers
+	}
+	h := fnv.New32a()
+	_, _ = h.Write(msg.Key)
+	return int(h.Sum32() % uint32(workers))
+}
+
+// work는 큐의 메시지를 batchSize개까지 모아 처리합니다. 밀린 메시지가 없으면 한 개씩 처리한다.
+func (c *consumerPool) work(ctx context.Context, queue <-chan *trackedMessage) {
+	for first := range queue {
+		batch := []*trackedMessage{first}
+	fill:
+		for len(batch) < c.batchSize {
+			select {
+			case next, ok := <-queue:
+				if !ok {
+					break fill
+				}
+				batch = append(batch, next)
+			default:
+				break fill
+			}
+		}
+		c.processBatch(ctx, batch)
+	}
+}
+
+// processBatch는 batch의 이벤트를 저장합니다. 같은 사용자의 다음 이벤트는 앞선 이벤트의 블록이 저장된 뒤에 계산해야 하므로,
+// 사용자마다 한 이벤트씩 모은 라운드를 순서대로 저장한다.
+func (c *consumerPool) processBatch(ctx context.Context, batch []*trackedMessage) {
+	pending := make([]pendingEvent, 0, len(batch))
+	for _, msg := range batch {
+		entry, attempts, err := c.decode(ctx, msg.msg)
+		if err != nil {
+			c.finish(ctx, msg, attempts, err)
+			continue
+		}
+		pending = append(pending, pendingEvent{msg: msg, entry: entry})
+	}
+
+	for len(pending) > 0 {
+		var round, rest []pendingEvent
+		users := make(map[string]bool, len(pending))
+		for _, p := range pending {
+			if users[p.entry.UserID] {
+				rest = append(rest, p)
+				continue
+			}
+			users[p.entry.UserID] = true
+			round = append(round, p)
+		}
+		c.storeRound(ctx, round)
+		pending = rest
+	}
+}
+
+// storeRound는 round의 블록을 계산해 한 트랜잭션으로 저장합니다.
+// 실패하면 이벤트마다 따로 재시도해, 한 이벤트의 오류가 같은 라운드의 다른 이벤트를 DLQ로 보내지 않도록 한다.
+func (c *consumerPool) storeRound(ctx context.Context, round []pendingEvent) {
+	blocks := make([]repository.Entry, 0, len(round))
+	var err error
+	for _, p := range round {
+		var block repository.Entry
+		if block, err = c.processor.prepare(ctx, p.entry); err != nil {
+			break
+		}
+		blocks = append(blocks, block)
+	}
+	if err == nil {
+		err = c.processor.repo.MergeEntries(ctx, blocks...)
+	}
+	if err == nil {
+		for _, p := range round {
+			c.offsets.done(p.msg)
+		}
+		return
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	for _, p := range round {
+		attempts, lastErr := 0, error(nil)
+		if len(round) == 1 {
+			attempts, lastErr = 1, err
+		}
+		attempts, lastErr = c.retry(ctx, p.entry, attempts, lastErr)
+		c.finish(ctx, p.msg, attempts, lastErr)
+	}
+}
+
+// retry는 attempts번 시도해 lastErr로 실패한 이벤트를 policy에 따라 지수 백오프로 다시 저장합니다.
+// 영구적인 오류(isPermanent)이거나 재시도를 모두 쓰면 마지막 오류와 시도 횟수를 반환한다.
+func (c *consumerPool) retry(ctx context.Context, entry repository.Entry, attempts int, lastErr error) (int, error) {
+	for {
+		if attempts > 0 {
+			if lastErr == nil || ctx.Err() != nil || isPermanent(lastErr) || attempts >= c.policy.maxAttempts {
+				return attempts, lastErr
+			}
+			delay := c.policy.delay(attempts)
+			c.logger.Warnw("failed to upsert timeline entry, retrying",
+				"event_id", entry.EventID,
+				"attempt", attempts,
+				"retry_in", delay.String(),
+				"error", lastErr,
+			)
+			if !sleepContext(ctx, delay) {
+				return attempts, ctx.Err()
+			}
+		}
+		attempts++
+		lastErr = c.processor.store(ctx, entry)
+	}
+}
+
+// decode는 msg를 디코딩합니다. 복호화처럼 일시적으로 실패하면 policy에 따라 다시 시도하고 시도 횟수를 함께 반환한다.
+func (c *consumerPool) decode(ctx context.Context, msg kafka.Message) (repository.Entry, int, error) {
+	for attempt := 1; ; attempt++ {
+		entry, err := decodeEvent(ctx, c.crypt, msg)
+		if err == nil || ctx.Err() != nil || isPermanent(err) || attempt >= c.policy.maxAttempts {
+			return entry, attempt, err
+		}
+		delay := c.policy.delay(attempt)
+		c.logger.Warnw("failed to decode timeline event, retrying",
+			"offset", msg.Offset,
+			"attempt", attempt,
+			"retry_in", delay.String(),
+			"error", err,
+		)
+		if !sleepContext(ctx, delay) {
+			return entry, attempt, ctx.Err()
+		}
+	}
+}
+
+// finish는 처리가 끝난 메시지를 커밋 대상으로 표시합니다. 실패한 메시지는 DLQ로 보낸 뒤에만 표시한다.
+// 종료로 처리가 중단된 메시지는 표시하지 않으므로, 다시 시작하면 그 메시지부터 처리한다. 삭제된 이벤트는 DLQ 없이 버린다.
+func (c *consumerPool) finish(ctx context.Context, msg *trackedMessage, attempts int, err error) {
+	if errors.Is(err, errEventPurged) {
+		c.logger.Infow("dropping purged timeline event", "offset", msg.msg.Offset)
+		err = nil
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		if c.deadLetters == nil {
+			c.logger.Errorw("dropping timeline event: dlq disabled", "offset", msg.msg.Offset, "attempts", attempts, "error", err)
+		} else if dlqErr := deadLetter(ctx, c.logger, c.deadLetters, c.policy, msg.msg, err, attempts); dlqErr != nil {
+			return
+		}
+	}
+	c.offsets.done(msg)
+}
+
+func (c *consumerPool) commit(ctx context.Context) {
+	msgs := c.offsets.committable()
+	if len(msgs) == 0 {
+		return
+	}
+	if err := c.consumer.Commit(ctx, msgs...); err != nil {
+		c.logger.Errorw("failed to commit kafka messages", "error", err)
+		return
+	}
+	c.offsets.committed(msgs)
+}
+
+// decodeEvent는 msg를 타임라인 이벤트로 디코딩하고 암호화된 메타데이터를 복호화합니다.
+// 복호화에 실패한 이벤트는 암호문인 채로 저장하지 않도록 오류를 반환한다. 파기된 키는 영구적인 오류이고, 키 조회 실패 등은 재시도한다.
+func decodeEvent(ctx context.Context, crypt *fieldcrypt.Encryptor, msg kafka.Message) (repository.Entry, error) {
+	var evt activityEvent
+	if err := json.Unmarshal(msg.Value, &evt); err != nil {
+		return repository.Entry{}, permanent(fmt.Errorf("decode kafka message: %w", err))
+	}
+	if evt.EventID == "" || evt.UserID == "" {
+		return repository.Entry{}, permanent(errors.New("event_id and user_id are required"))
+	}
+	if crypt != nil && evt.Metadata != nil {
+		if _, err := crypt.DecryptFields(ctx, evt.UserID, evt.Metadata); err != nil {
+			err = fmt.Errorf("decrypt event metadata: %w", err)
+			if errors.Is(err, fieldcrypt.ErrKeyShredded) {
+				return repository.Entry{}, permanent(err)
+			}
+			return repository.Entry{}, err
+		}
+	}
+
+	return repository.Entry{
+		EventID:   evt.EventID,
+		UserID:    evt.UserID,
+		Source:    evt.Source,
+		StartedAt: evt.StartedAt,
+		EndedAt:   evt.EndedAt,
+		Metadata:  evt.Metadata,
+		DeviceID:  evt.DeviceID,
+	}, nil
+}
+
+// offsetTracker는 파티션별로 가져온 메시지와 처리가 끝난 메시지를 추적해 커밋할 오프셋을 계산합니다.
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[int]*partitionOffsets
+}
+
+// partitionOffsets는 한 파티션에서 가져온 순서대로의 미완료 메시지와, 앞선 메시지가 모두 끝난 마지막 메시지입니다.
+type partitionOffsets struct {
+	pending []*trackedMessage
+	last    int64
+	commit  *kafka.Message
+}
+
+// trackedMessage는 처리 중인 메시지입니다.
+type trackedMessage struct {
+	msg       kafka.Message
+	partition *partitionOffsets
+	done      bool
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{partitions: map[int]*partitionOffsets{}}
+}
+
+// track은 가져온 msg를 미완료로 기록합니다.
+func (o *offsetTracker) track(msg kafka.Message) *trackedMessage {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	p := o.partitions[msg.Partition]
+	if p == nil || msg.Offset <= p.last {
+		// 리밸런스로 커밋된 위치부터 다시 읽으면 이전 기록을 버린다. 이전 메시지가 끝나도 새 기록에는 반영되지 않는다.
+		p = &partitionOffsets{}
+		o.partitions[msg.Partition] = p
+	}
+	p.last = msg.Offset
+	t := &trackedMessage{msg: msg, partition: p}
+	p.pending = append(p.pending, t)
+	return t
+}
+
+// done은 t의 처리가 끝났다고 표시하고, 파티션 맨 앞부터 이어서 끝난 메시지까지 커밋 위치를 옮깁니다.
+func (o *offsetTracker) done(t *trackedMessage) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	t.done = true
+	// 커밋에는 위치만 필요하므로 값은 놓아 준다.
+	t.msg = kafka.Message{Topic: t.msg.Topic, Partition: t.msg.Partition, Offset: t.msg.Offset}
+	p := t.partition
+	for len(p.pending) > 0 && p.pending[0].done {
+		msg := p.pending[0].msg
+		p.commit = &msg
+		p.pending[0] = nil
+		p.pending = p.pending[1:]
+	}
+}
+
+// committable은 파티션마다 아직 커밋하지 않은 마지막 커밋 위치를 반환합니다.
+func (o *offsetTracker) committable() []kafka.Message {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var msgs []kafka.Message
+	for _, p := range o.partitions {
+		if p.commit != nil {
+			msgs = append(msgs, *p.commit)
+		}
+	}
+	return msgs
+}
+
+// committed는 커밋에 성공한 위치를 지웁니다. 그사이 커밋 위치가 더 나아갔으면 남겨 둔다.
+func (o *offsetTracker) committed(msgs []kafka.Message) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, msg := range msgs {
+		if p := o.partitions[msg.Partition]; p != nil && p.commit != nil && p.commit.Offset == msg.Offset {
+			p.commit = nil
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"daylog/services/timeline/repository"
+
+	"github.com/google/uuid"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// cursorPayload는 next_cursor에 담기는 값입니다. 클라이언트에는 base64url로 인코딩한 불투명 문자열로만 노출한다.
+type cursorPayload struct {
+	StartedAt  time.Time `json:"s"`
+	TimelineID string    `json:"id"`
+}
+
+func encodeCursor(c repository.TimelineCursor) string {
+	raw, _ := json.Marshal(cursorPayload{StartedAt: c.StartedAt.UTC(), TimelineID: c.TimelineID})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s string) (*repository.TimelineCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	var p cursorPayload
+	if err := json.Unmarshal(raw, &p); err != nil || p.StartedAt.IsZero() {
+		return nil, errInvalidCursor
+	}
+	if _, err := uuid.Parse(p.TimelineID); err != nil {
+		return nil, errInvalidCursor
+	}
+	return &repository.TimelineCursor{StartedAt: p.StartedAt, TimelineID: p.TimelineID}, nil
+}
+
+// timelineQuery는 타임라인 조회 파라미터(cursor, before, after, category)를 해석합니다.
+// category는 쉼표로 구분하거나 여러 번 지정할 수 있다.
+func timelineQuery(values url.Values) (repository.TimelineQuery, error) {
+	var q repository.TimelineQuery
+	if raw := values.Get("cursor"); raw != "" {
+		cursor, err := decodeCursor(raw)
+		if err != nil {
+			return q, err
+		}
+		q.Cursor = cursor
+	}
+	for name, dst := range map[string]*time.Time{"before": &q.Before, "after": &q.After} {
+		raw := values.Get(name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return q, fmt.Errorf("%s must be an RFC3339 timestamp", name)
+		}
+		*dst = t
+	}
+	if !q.Before.IsZero() && !q.After.IsZero() && !q.After.Before(q.Before) {
+		return q, errors.New("after must be earlier than before")
+	}
+	for _, raw := range values["category"] {
+		for _, category := range strings.Split(raw, ",") {
+			if category = strings.TrimSpace(category); category != "" {
+				q.Categories = append(q.Categories, category)
+			}
+		}
+	}
+	return q, nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"daylog/services/timeline/dayview"
+
+	"github.com/gorilla/mux"
+)
+
+// handleGetDay는 사용자 시간대 기준 하루의 타임라인을 빈 시간까지 채워 반환합니다.
+func (s *server) handleGetDay(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+	date := mux.Vars(r)["date"]
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	loc, err := s.userLocation(ctx, userID)
+	if err != nil {
+		s.logger.Errorw("failed to load user timezone", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch timeline day"})
+		return
+	}
+	start, end, err := dayview.Bounds(date, loc)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	blocks, err := s.repo.BlocksBetween(ctx, userID, start, end)
+	if err != nil {
+		s.logger.Errorw("failed to fetch timeline day", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch timeline day"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, dayview.Build(date, loc, start, end, blocks))
+}
+
+// userLocation은 사용자의 user_settings.timezone을 반환합니다. 설정이 없거나 잘못된 값이면 기본 시간대를 사용합니다.
+func (s *server) userLocation(ctx context.Context, userID string) (*time.Location, error) {
+	name, err := s.repo.UserTimezone(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return s.defaultLocation, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		s.logger.Warnw("invalid user timezone, using default", "user_id", userID, "timezone", name)
+		return s.defaultLocation, nil
+	}
+	return loc, nil
+}
@@ -0,0 +1,178 @@
+// Package dayview는 사용자 시간대 기준 하루의 타임라인을 겹치지 않는 구간으로 펼칩니다.
+// 블록이 없는 시간은 "unknown" 구간으로 채워 구간 길이의 합이 그날의 길이(서머타임 전환일은 23시간 또는 25시간)와 같다.
+package dayview
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"daylog/services/timeline/repository"
+)
+
+// CategoryUnknown은 블록이 없는 시간을 나타내는 카테고리입니다.
+const CategoryUnknown = "unknown"
+
+// Day는 하루의 타임라인입니다. StartedAt/EndedAt은 그날 첫 순간과 다음 날 첫 순간이다.
+type Day struct {
+	Date            string           `json:"date"`
+	Timezone        string           `json:"timezone"`
+	StartedAt       time.Time        `json:"started_at"`
+	EndedAt         time.Time        `json:"ended_at"`
+	DurationSeconds int64            `json:"duration_seconds"`
+	Blocks          []Segment        `json:"blocks"`
+	Totals          map[string]int64 `json:"totals_seconds"`
+}
+
+// Segment는 하루 안에서 블록 하나가 차지하는 구간입니다.
+// Clipped는 원래 블록이 자정이나 더 구체적인 블록 때문에 잘렸는지 여부이며, unknown 구간은 TimelineID가 비어 있다.
+type Segment struct {
+	TimelineID      string         `json:"timeline_id,omitempty"`
+	Category        string         `json:"category"`
+	UserVerified    bool           `json:"user_verified"`
+	StartedAt       time.Time      `json:"started_at"`
+	EndedAt         time.Time      `json:"ended_at"`
+	DurationSeconds int64          `json:"duration_seconds"`
+	Confidence      float64        `json:"confidence"`
+	Source          string         `json:"source,omitempty"`
+	GeoContext      map[string]any `json:"geo_context,omitempty"`
+	SourceEvents    []string       `json:"source_event_ids,omitempty"`
+	Clipped         bool           `json:"clipped"`
+}
+
+// Bounds는 loc에서 date(YYYY-MM-DD)의 첫 순간과 다음 날 첫 순간을 반환합니다.
+// 자정에 서머타임이 시작되는 시간대(자정이 존재하지 않는 날)는 전환 직후가 그날의 첫 순간이다.
+func Bounds(date string, loc *time.Location) (start, end time.Time, err error) {
+	// 달력 날짜만 읽는다. loc에서 파싱하면 존재하지 않는 자정이 전날로 정규화된다.
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("date must be YYYY-MM-DD: %w", err)
+	}
+	y, m, d := day.Date()
+	return midnight(y, m, d, loc), midnight(y, m, d+1, loc), nil
+}
+
+func midnight(y int, m time.Month, d int, loc *time.Location) time.Time {
+	t := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	if _, _, td := t.Date(); td != time.Date(y, m, d, 12, 0, 0, 0, loc).Day() {
+		// 존재하지 않는 자정이 전날 시각으로 정규화되었다. 전환 시점(그 오프셋 구간의 끝)부터가 그날이다.
+		_, t = t.ZoneBounds()
+	}
+	return t
+}
+
+// Build는 [start, end)와 겹치는 blocks로 하루 타임라인을 만듭니다.
+// 블록이 겹치는 시간에는 더 늦게 시작한 블록(예: 장소 체류 중의 운동)을 보여주고,
+// 시작이 같으면 신뢰도가 높은 블록을 보여준다. 시각은 loc 기준으로 표시한다.
+func Build(date string, loc *time.Location, start, end time.Time, blocks []repository.Block) Day {
+	clipped := make([]repository.Block, 0, len(blocks))
+	points := []time.Time{start, end}
+	for _, b := range blocks {
+		if !b.EndedAt.After(start) || !b.StartedAt.Before(end) || !b.EndedAt.After(b.StartedAt) {
+			continue
+		}
+		clipped = append(clipped, b)
+		points = append(points, maxTime(b.StartedAt, start), minTime(b.EndedAt, end))
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Before(points[j]) })
+
+	day := Day{
+		Date:            date,
+		Timezone:        loc.String(),
+		StartedAt:       start.In(loc),
+		EndedAt:         end.In(loc),
+		DurationSeconds: int64(end.Sub(start) / time.Second),
+		Blocks:          []Segment{},
+		Totals:          map[string]int64{},
+	}
+	for i := 0; i+1 < len(points); i++ {
+		from, to := points[i], points[i+1]
+		if !to.After(from) {
+			continue
+		}
+		top := topBlock(clipped, from, to)
+		if n := len(day.Blocks); n > 0 && day.Blocks[n-1].TimelineID == topID(top) {
+			day.Blocks[n-1].EndedAt = to.In(loc)
+			continue
+		}
+		day.Blocks = append(day.Blocks, segment(top, from.In(loc), to.In(loc)))
+	}
+
+	for i := range day.Blocks {
+		seg := &day.Blocks[i]
+		seg.DurationSeconds = int64(seg.EndedAt.Sub(seg.StartedAt) / time.Second)
+		if seg.TimelineID != "" {
+			for _, b := range clipped {
+				if b.TimelineID == seg.TimelineID {
+					seg.Clipped = !b.StartedAt.Equal(seg.StartedAt) || !b.EndedAt.Equal(seg.EndedAt)
+					break
+				}
+			}
+		}
+		day.Totals[seg.Category] += seg.DurationSeconds
+	}
+	return day
+}
+
+// topBlock은 [from, to) 전체를 덮는 블록 중 보여줄 블록을 고릅니다. 없으면 nil이다.
+func topBlock(blocks []repository.Block, from, to time.Time) *repository.Block {
+	var top *repository.Block
+	for i := range blocks {
+		b := &blocks[i]
+		if b.StartedAt.After(from) || b.EndedAt.Before(to) {
+			continue
+		}
+		if top == nil || above(b, top) {
+			top = b
+		}
+	}
+	return top
+}
+
+func above(a, b *repository.Block) bool {
+	if !a.StartedAt.Equal(b.StartedAt) {
+		return a.StartedAt.After(b.StartedAt)
+	}
+	if a.Confidence != b.Confidence {
+		return a.Confidence > b.Confidence
+	}
+	return a.TimelineID < b.TimelineID
+}
+
+func topID(b *repository.Block) string {
+	if b == nil {
+		return ""
+	}
+	return b.TimelineID
+}
+
+func segment(b *repository.Block, from, to time.Time) Segment {
+	if b == nil {
+		return Segment{Category: CategoryUnknown, StartedAt: from, EndedAt: to}
+	}
+	return Segment{
+		TimelineID:   b.TimelineID,
+		Category:     b.Category,
+		UserVerified: b.UserVerified,
+		StartedAt:    from,
+		EndedAt:      to,
+		Confidence:   b.Confidence,
+		Source:       b.Source,
+		GeoContext:   b.GeoContext,
+		SourceEvents: b.SourceEvents,
+	}
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
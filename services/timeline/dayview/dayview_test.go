@@ -0,0 +1,271 @@
+package dayview
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // 테스트 환경에 시간대 데이터가 없어도 같은 결과를 얻도록 내장한다.
+
+	"daylog/services/timeline/repository"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("load %s: %v", name, err)
+	}
+	return loc
+}
+
+func TestBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		zone     string
+		date     string
+		start    string // RFC3339, 그날 첫 순간
+		duration int64
+	}{
+		{name: "평일", zone: "America/New_York", date: "2024-03-09", start: "2024-03-09T00:00:00-05:00", duration: 86400},
+		{name: "서머타임 시작", zone: "America/New_York", date: "2024-03-10", start: "2024-03-10T00:00:00-05:00", duration: 82800},
+		{name: "서머타임 종료", zone: "America/New_York", date: "2024-11-03", start: "2024-11-03T00:00:00-04:00", duration: 90000},
+		{name: "자정에 서머타임 시작(산티아고)", zone: "America/Santiago", date: "2024-09-08", start: "2024-09-08T01:00:00-03:00", duration: 82800},
+		{name: "자정 서머타임 시작 전날", zone: "America/Santiago", date: "2024-09-07", start: "2024-09-07T00:00:00-04:00", duration: 86400},
+		{name: "자정에 서머타임 시작(베이루트)", zone: "Asia/Beirut", date: "2024-03-31", start: "2024-03-31T01:00:00+03:00", duration: 82800},
+		{name: "자정에 서머타임 종료(산티아고)", zone: "America/Santiago", date: "2024-04-06", start: "2024-04-06T00:00:00-03:00", duration: 90000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := mustLoad(t, tt.zone)
+			start, end, err := Bounds(tt.date, loc)
+			if err != nil {
+				t.Fatalf("Bounds: %v", err)
+			}
+			want, _ := time.Parse(time.RFC3339, tt.start)
+			if !start.Equal(want) {
+				t.Errorf("start = %s, want %s", start.In(loc).Format(time.RFC3339), tt.start)
+			}
+			if got := int64(end.Sub(start) / time.Second); got != tt.duration {
+				t.Errorf("duration = %d, want %d", got, tt.duration)
+			}
+
+			day := Build(tt.date, loc, start, end, nil)
+			if day.DurationSeconds != tt.duration {
+				t.Errorf("DurationSeconds = %d, want %d", day.DurationSeconds, tt.duration)
+			}
+			if len(day.Blocks) != 1 || day.Blocks[0].Category != CategoryUnknown || day.Blocks[0].DurationSeconds != tt.duration {
+				t.Errorf("empty day blocks = %+v, want one unknown segment of %d seconds", day.Blocks, tt.duration)
+			}
+		})
+	}
+}
+
+func TestBoundsInvalidDate(t *testing.T) {
+	if _, _, err := Bounds("2024/03/10", time.UTC); err == nil {
+		t.Fatal("Bounds accepted a malformed date")
+	}
+}
+
+func TestBuild(t *testing.T) {
+	type block struct {
+		id, category string
+		start, end   string // 그날 시간대의 "15:04" 또는 전날·다음 날은 RFC3339
+		confidence   float64
+	}
+	type want struct {
+		id, category string
+		start, end   string
+		clipped      bool
+	}
+
+	tests := []struct {
+		name   string
+		zone   string
+		date   string
+		blocks []block
+		want   []want
+		totals map[string]int64
+	}{
+		{
+			name: "자정을 넘는 블록은 잘린다",
+			zone: "Asia/Seoul",
+			date: "2024-05-01",
+			blocks: []block{
+				{id: "sleep", category: "sleep", start: "2024-04-30T23:00:00+09:00", end: "07:00", confidence: 0.9},
+				{id: "late", category: "screen_time", start: "22:00", end: "2024-05-02T01:30:00+09:00", confidence: 0.6},
+			},
+			want: []want{
+				{id: "sleep", category: "sleep", start: "00:00", end: "07:00", clipped: true},
+				{category: CategoryUnknown, start: "07:00", end: "22:00"},
+				{id: "late", category: "screen_time", start: "22:00", end: "24:00", clipped: true},
+			},
+			totals: map[string]int64{"sleep": 7 * 3600, CategoryUnknown: 15 * 3600, "screen_time": 2 * 3600},
+		},
+		{
+			name: "늦게 시작한 블록이 앞선 블록을 가린다",
+			zone: "Asia/Seoul",
+			date: "2024-05-01",
+			blocks: []block{
+				{id: "office", category: "location", start: "09:00", end: "18:00", confidence: 0.9},
+				{id: "meeting", category: "calendar", start: "10:00", end: "11:00", confidence: 0.6},
+			},
+			want: []want{
+				{category: CategoryUnknown, start: "00:00", end: "09:00"},
+				{id: "office", category: "location", start: "09:00", end: "10:00", clipped: true},
+				{id: "meeting", category: "calendar", start: "10:00", end: "11:00"},
+				{id: "office", category: "location", start: "11:00", end: "18:00", clipped: true},
+				{category: CategoryUnknown, start: "18:00", end: "24:00"},
+			},
+			totals: map[string]int64{"location": 8 * 3600, "calendar": 3600, CategoryUnknown: 15 * 3600},
+		},
+		{
+			name: "시작이 같으면 신뢰도가 높은 블록",
+			zone: "UTC",
+			date: "2024-05-01",
+			blocks: []block{
+				{id: "a", category: "screen_time", start: "08:00", end: "09:00", confidence: 0.5},
+				{id: "b", category: "health", start: "08:00", end: "09:00", confidence: 0.8},
+			},
+			want: []want{
+				{category: CategoryUnknown, start: "00:00", end: "08:00"},
+				{id: "b", category: "health", start: "08:00", end: "09:00"},
+				{category: CategoryUnknown, start: "09:00", end: "24:00"},
+			},
+			totals: map[string]int64{"health": 3600, CategoryUnknown: 23 * 3600},
+		},
+		{
+			name: "서머타임 시작일의 23시간",
+			zone: "America/New_York",
+			date: "2024-03-10",
+			blocks: []block{
+				{id: "sleep", category: "sleep", start: "2024-03-09T23:00:00-05:00", end: "2024-03-10T07:00:00-04:00", confidence: 0.9},
+			},
+			want: []want{
+				{id: "sleep", category: "sleep", start: "2024-03-10T00:00:00-05:00", end: "2024-03-10T07:00:00-04:00", clipped: true},
+				{category: CategoryUnknown, start: "2024-03-10T07:00:00-04:00", end: "2024-03-11T00:00:00-04:00"},
+			},
+			totals: map[string]int64{"sleep": 6 * 3600, CategoryUnknown: 17 * 3600},
+		},
+		{
+			name: "서머타임 종료일의 25시간",
+			zone: "America/New_York",
+			date: "2024-11-03",
+			blocks: []block{
+				{id: "night", category: "screen_time", start: "2024-11-03T00:30:00-04:00", end: "2024-11-03T01:30:00-05:00", confidence: 0.7},
+			},
+			want: []want{
+				{category: CategoryUnknown, start: "2024-11-03T00:00:00-04:00", end: "2024-11-03T00:30:00-04:00"},
+				{id: "night", category: "screen_time", start: "2024-11-03T00:30:00-04:00", end: "2024-11-03T01:30:00-05:00"},
+				{category: CategoryUnknown, start: "2024-11-03T01:30:00-05:00", end: "2024-11-04T00:00:00-05:00"},
+			},
+			totals: map[string]int64{"screen_time": 2 * 3600, CategoryUnknown: 23 * 3600},
+		},
+		{
+			name: "자정에 서머타임이 시작되는 날",
+			zone: "America/Santiago",
+			date: "2024-09-08",
+			blocks: []block{
+				{id: "sleep", category: "sleep", start: "2024-09-07T23:00:00-04:00", end: "2024-09-08T08:00:00-03:00", confidence: 0.9},
+			},
+			want: []want{
+				{id: "sleep", category: "sleep", start: "2024-09-08T01:00:00-03:00", end: "2024-09-08T08:00:00-03:00", clipped: true},
+				{category: CategoryUnknown, start: "2024-09-08T08:00:00-03:00", end: "2024-09-09T00:00:00-03:00"},
+			},
+			totals: map[string]int64{"sleep": 7 * 3600, CategoryUnknown: 16 * 3600},
+		},
+		{
+			name: "그날과 겹치지 않거나 길이가 없는 블록은 무시",
+			zone: "UTC",
+			date: "2024-05-01",
+			blocks: []block{
+				{id: "before", category: "sleep", start: "2024-04-30T20:00:00Z", end: "2024-05-01T00:00:00Z", confidence: 0.9},
+				{id: "empty", category: "health", start: "12:00", end: "12:00", confidence: 0.9},
+			},
+			want: []want{
+				{category: CategoryUnknown, start: "00:00", end: "24:00"},
+			},
+			totals: map[string]int64{CategoryUnknown: 24 * 3600},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := mustLoad(t, tt.zone)
+			start, end, err := Bounds(tt.date, loc)
+			if err != nil {
+				t.Fatalf("Bounds: %v", err)
+			}
+			at := func(s string) time.Time {
+				t.Helper()
+				if s == "24:00" {
+					return end
+				}
+				if ts, err := time.Parse(time.RFC3339, s); err == nil {
+					return ts
+				}
+				clock, err := time.ParseInLocation("2006-01-02 15:04", tt.date+" "+s, loc)
+				if err != nil {
+					t.Fatalf("parse %q: %v", s, err)
+				}
+				return clock
+			}
+
+			blocks := make([]repository.Block, 0, len(tt.blocks))
+			for _, b := range tt.blocks {
+				blocks = append(blocks, repository.Block{
+					TimelineID: b.id,
+					Category:   b.category,
+					StartedAt:  at(b.start),
+					EndedAt:    at(b.end),
+					Confidence: b.confidence,
+				})
+			}
+
+			day := Build(tt.date, loc, start, end, blocks)
+
+			if len(day.Blocks) != len(tt.want) {
+				t.Fatalf("got %d segments, want %d: %+v", len(day.Blocks), len(tt.want), day.Blocks)
+			}
+			var sum int64
+			for i, w := range tt.want {
+				seg := day.Blocks[i]
+				if seg.TimelineID != w.id || seg.Category != w.category || seg.Clipped != w.clipped {
+					t.Errorf("segment %d = {%q %q clipped=%v}, want {%q %q clipped=%v}",
+						i, seg.TimelineID, seg.Category, seg.Clipped, w.id, w.category, w.clipped)
+				}
+				if !seg.StartedAt.Equal(at(w.start)) || !seg.EndedAt.Equal(at(w.end)) {
+					t.Errorf("segment %d = [%s, %s), want [%s, %s)", i,
+						seg.StartedAt.Format(time.RFC3339), seg.EndedAt.Format(time.RFC3339),
+						at(w.start).In(loc).Format(time.RFC3339), at(w.end).In(loc).Format(time.RFC3339))
+				}
+				if seg.StartedAt.Location() != loc {
+					t.Errorf("segment %d is in %s, want %s", i, seg.StartedAt.Location(), loc)
+				}
+				if got := int64(seg.EndedAt.Sub(seg.StartedAt) / time.Second); seg.DurationSeconds != got {
+					t.Errorf("segment %d DurationSeconds = %d, want %d", i, seg.DurationSeconds, got)
+				}
+				if i > 0 && !seg.StartedAt.Equal(day.Blocks[i-1].EndedAt) {
+					t.Errorf("segment %d starts at %s, previous ends at %s", i, seg.StartedAt, day.Blocks[i-1].EndedAt)
+				}
+				sum += seg.DurationSeconds
+			}
+
+			if sum != day.DurationSeconds {
+				t.Errorf("segments sum to %d seconds, day is %d", sum, day.DurationSeconds)
+			}
+			var totals int64
+			for category, seconds := range day.Totals {
+				totals += seconds
+				if tt.totals[category] != seconds {
+					t.Errorf("Totals[%q] = %d, want %d", category, seconds, tt.totals[category])
+				}
+			}
+			if len(day.Totals) != len(tt.totals) {
+				t.Errorf("Totals = %v, want %v", day.Totals, tt.totals)
+			}
+			if totals != day.DurationSeconds {
+				t.Errorf("Totals sum to %d seconds, day is %d", totals, day.DurationSeconds)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+// Package dedup은 여러 기기가 같은 활동을 각각 보고한 이벤트를 찾아 대표 이벤트 하나로 합칩니다.
+// 같은 사용자의 이벤트 중 다른 기기가 보고했고 구간이 설정한 비율 이상 겹치며 활동 종류가 호환되는 이벤트를 중복으로 보며,
+// 소스 우선순위 규칙으로 대표 이벤트를 고르고 나머지는 대표 항목의 source_event_ids에 남긴다.
+package dedup
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"daylog/services/timeline/repository"
+)
+
+// sourceKinds는 소스 이름과 소스 종류의 매핑입니다(수집 서비스 validation 패키지의 소스 목록과 같다).
+var sourceKinds = map[string]string{
+	"screen_time":         "screen_time",
+	"ios_screen_time":     "screen_time",
+	"android_usage_stats": "screen_time",
+	"location":            "location",
+	"ios_location":        "location",
+	"android_location":    "location",
+	"calendar":            "calendar",
+	"health":              "health",
+	"apple_health":        "health",
+	"google_fit":          "health",
+}
+
+// Config는 중복 판정 설정입니다.
+type Config struct {
+	// OverlapRatio는 두 구간의 교집합/합집합 비율 하한입니다(0 초과 1 이하).
+	OverlapRatio float64
+	// Kinds는 중복 제거를 적용할 소스 종류입니다. 화면 사용 시간처럼 기기마다 별개인 활동은 제외한다.
+	Kinds []string
+	// SourcePriority는 대표 이벤트 선택 시 앞에 있을수록 우선하는 소스 이름입니다.
+	SourcePriority []string
+}
+
+// Deduplicator는 Config에 따라 중복 이벤트 묶음과 대표 이벤트를 결정합니다.
+type Deduplicator struct {
+	ratio    float64
+	kinds    map[string]bool
+	priority map[string]int
+}
+
+// New는 Deduplicator를 생성합니다.
+func New(cfg Config) (*Deduplicator, error) {
+	if cfg.OverlapRatio <= 0 || cfg.OverlapRatio > 1 {
+		return nil, fmt.Errorf("overlap ratio must be in (0, 1], got %v", cfg.OverlapRatio)
+	}
+	d := &Deduplicator{
+		ratio:    cfg.OverlapRatio,
+		kinds:    make(map[string]bool, len(cfg.Kinds)),
+		priority: make(map[string]int, len(cfg.SourcePriority)),
+	}
+	for _, kind := range cfg.Kinds {
+		if kind = strings.TrimSpace(kind); kind != "" {
+			d.kinds[kind] = true
+		}
+	}
+	for i, source := range cfg.SourcePriority {
+		source = strings.TrimSpace(source)
+		if _, dup := d.priority[source]; source != "" && !dup {
+			d.priority[source] = i
+		}
+	}
+	return d, nil
+}
+
+// Applies는 evt가 중복 제거 대상 종류인지 확인합니다.
+func (d *Deduplicator) Applies(evt repository.Entry) bool {
+	return d.kinds[sourceKinds[evt.Source]]
+}
+
+// Duplicate는 a와 b가 같은 활동을 다른 기기가 각각 보고한 이벤트인지 확인합니다.
+// 한 기기가 보고한 이벤트끼리는 겹치더라도 별개의 기록이므로 중복으로 보지 않는다.
+func (d *Deduplicator) Duplicate(a, b repository.Entry) bool {
+	return a.EventID != b.EventID && !sameDevice(a, b) && d.Applies(a) && d.compatible(a, b) && d.overlapRatio(a, b) >= d.ratio
+}
+
+// Resolve는 events를 중복 묶음으로 나눠 묶음마다 대표 이벤트를 고릅니다.
+// 반환값은 대표 이벤트 목록(입력 순서와 무관)과, 대표 event_id별로 그 대표에 합쳐진 나머지 이벤트입니다.
+func (d *Deduplicator) Resolve(events []repository.Entry) (canonical []repository.Entry, suppressed map[string][]repository.Entry) {
+	ordered := append([]repository.Entry(nil), events...)
+	sort.SliceStable(ordered, func(i, j int) bool { return d.Preferred(ordered[i], ordered[j]) })
+
+	suppressed = map[string][]repository.Entry{}
+	absorbed := make([]bool, len(ordered))
+	for i, evt := range ordered {
+		if absorbed[i] {
+			continue
+		}
+		canonical = append(canonical, evt)
+		for j := i + 1; j < len(ordered); j++ {
+			if !absorbed[j] && d.Duplicate(evt, ordered[j]) {
+				absorbed[j] = true
+				suppressed[evt.EventID] = append(suppressed[evt.EventID], ordered[j])
+			}
+		}
+	}
+	return canonical, suppressed
+}
+
+// compatible은 두 이벤트가 같은 활동을 나타낼 수 있는지 확인합니다.
+// 소스 종류가 같아야 하며, 건강 데이터는 type(workout, sleep 등)도 같아야 한다.
+// 운동 종류는 기기마다 이름이 다를 수 있어 양쪽 모두 값이 있고 다른 경우에만 구분한다.
+func (d *Deduplicator) compatible(a, b repository.Entry) bool {
+	kind := sourceKinds[a.Source]
+	if kind == "" || kind != sourceKinds[b.Source] {
+		return false
+	}
+	if kind != "health" {
+		return true
+	}
+	if metaString(a, "type") != metaString(b, "type") {
+		return false
+	}
+	wa, wb := metaString(a, "workout_type"), metaString(b, "workout_type")
+	return wa == "" || wb == "" || strings.EqualFold(wa, wb)
+}
+
+// sameDevice는 두 이벤트가 같은 기기에서 보고되었는지 확인합니다.
+// 기기 인증 없이 수집되어 한쪽이라도 device_id가 없으면 기기를 구분할 수 없으므로, 같은 소스의 이벤트를 같은 기기로 본다.
+func sameDevice(a, b repository.Entry) bool {
+	if a.DeviceID != "" && b.DeviceID != "" {
+		return a.DeviceID == b.DeviceID
+	}
+	return a.Source == b.Source
+}
+
+// overlapRatio는 두 구간의 교집합/합집합 비율입니다. 길이가 0인 두 이벤트는 시작 시각이 같을 때만 1이다.
+func (d *Deduplicator) overlapRatio(a, b repository.Entry) float64 {
+	start, end := maxTime(a.StartedAt, b.StartedAt), minTime(a.EndedAt, b.EndedAt)
+	union := maxTime(a.EndedAt, b.EndedAt).Sub(minTime(a.StartedAt, b.StartedAt))
+	if union <= 0 {
+		if a.StartedAt.Equal(b.StartedAt) {
+			return 1
+		}
+		return 0
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return float64(end.Sub(start)) / float64(union)
+}
+
+// Preferred는 대표 이벤트 선택 순서입니다. 소스 우선순위, 긴 구간, 이른 시작, event_id 순으로 비교해 결과가 결정적이다.
+func (d *Deduplicator) Preferred(a, b repository.Entry) bool {
+	if pa, pb := d.rank(a.Source), d.rank(b.Source); pa != pb {
+		return pa < pb
+	}
+	if la, lb := a.EndedAt.Sub(a.StartedAt), b.EndedAt.Sub(b.StartedAt); la != lb {
+		return la > lb
+	}
+	if !a.StartedAt.Equal(b.StartedAt) {
+		return a.StartedAt.Before(b.StartedAt)
+	}
+	return a.EventID < b.EventID
+}
+
+func (d *Deduplicator) rank(source string) int {
+	if p, ok := d.priority[source]; ok {
+		return p
+	}
+	return len(d.priority)
+}
+
+// SourceKind는 소스 이름의 소스 종류(screen_time, location, calendar, health)입니다. 알 수 없는 소스는 빈 문자열이다.
+func SourceKind(source string) string {
+	return sourceKinds[source]
+}
+
+func metaString(e repository.Entry, key string) string {
+	v, _ := e.Metadata[key].(string)
+	return v
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
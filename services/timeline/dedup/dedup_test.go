@@ -0,0 +1,130 @@
+package dedup
+
+import (
+	"testing"
+	"time"
+
+	"daylog/services/timeline/repository"
+)
+
+func newDeduplicator(t *testing.T) *Deduplicator {
+	t.Helper()
+	d, err := New(Config{
+		OverlapRatio:   0.7,
+		Kinds:          []string{"health", "location", "calendar"},
+		SourcePriority: []string{"apple_health", "google_fit", "health"},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return d
+}
+
+func workout(id, source, device string, start time.Time, minutes int) repository.Entry {
+	return repository.Entry{
+		EventID:   id,
+		UserID:    "7d0c5c1e-2f7e-4a55-9a57-0d8a4f1b2c3d",
+		Source:    source,
+		StartedAt: start,
+		EndedAt:   start.Add(time.Duration(minutes) * time.Minute),
+		Metadata:  map[string]interface{}{"type": "workout", "workout_type": "running"},
+		DeviceID:  device,
+	}
+}
+
+func TestDuplicate(t *testing.T) {
+	d := newDeduplicator(t)
+	start := time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)
+	const (
+		watch = "0b7e6f0a-1c2d-4e5f-8a9b-0c1d2e3f4a5b"
+		phone = "5f4e3d2c-1b0a-4f9e-8d7c-6b5a4f3e2d1c"
+	)
+
+	tests := []struct {
+		name string
+		a, b repository.Entry
+		want bool
+	}{
+		{
+			name: "다른 기기의 같은 운동",
+			a:    workout("a", "apple_health", watch, start, 30),
+			b:    workout("b", "apple_health", phone, start.Add(time.Minute), 30),
+			want: true,
+		},
+		{
+			name: "같은 기기의 겹치는 운동",
+			a:    workout("a", "apple_health", watch, start, 30),
+			b:    workout("b", "apple_health", watch, start.Add(time.Minute), 30),
+			want: false,
+		},
+		{
+			name: "같은 기기가 다른 소스로 보고",
+			a:    workout("a", "apple_health", watch, start, 30),
+			b:    workout("b", "health", watch, start, 30),
+			want: false,
+		},
+		{
+			name: "기기 정보 없는 같은 소스",
+			a:    workout("a", "google_fit", "", start, 30),
+			b:    workout("b", "google_fit", "", start, 30),
+			want: false,
+		},
+		{
+			name: "기기 정보 없는 다른 소스",
+			a:    workout("a", "apple_health", "", start, 30),
+			b:    workout("b", "google_fit", phone, start, 30),
+			want: true,
+		},
+		{
+			name: "겹침 비율 미달",
+			a:    workout("a", "apple_health", watch, start, 30),
+			b:    workout("b", "apple_health", phone, start.Add(20*time.Minute), 30),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.Duplicate(tt.a, tt.b); got != tt.want {
+				t.Errorf("Duplicate(a, b) = %v, want %v", got, tt.want)
+			}
+			if got := d.Duplicate(tt.b, tt.a); got != tt.want {
+				t.Errorf("Duplicate(b, a) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveKeepsSameDeviceEvents(t *testing.T) {
+	d := newDeduplicator(t)
+	start := time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)
+	const (
+		watch = "0b7e6f0a-1c2d-4e5f-8a9b-0c1d2e3f4a5b"
+		phone = "5f4e3d2c-1b0a-4f9e-8d7c-6b5a4f3e2d1c"
+	)
+
+	events := []repository.Entry{
+		workout("watch-1", "apple_health", watch, start, 30),
+		workout("watch-2", "apple_health", watch, start.Add(2*time.Minute), 30),
+		workout("phone-1", "google_fit", phone, start, 30),
+	}
+	canonical, suppressed := d.Resolve(events)
+
+	if len(canonical) != 2 {
+		t.Fatalf("canonical = %d events, want 2 (one per watch workout)", len(canonical))
+	}
+	total := len(canonical)
+	for _, evts := range suppressed {
+		total += len(evts)
+	}
+	if total != len(events) {
+		t.Errorf("canonical + suppressed = %d events, want %d", total, len(events))
+	}
+	for _, evts := range suppressed {
+		for _, evt := range evts {
+			if evt.DeviceID == watch {
+				t.Errorf("watch event %s was suppressed as a duplicate", evt.EventID)
+			}
+		}
+	}
+}
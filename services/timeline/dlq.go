@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"daylog/services/common/config"
+	"daylog/services/common/messaging"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// DLQ 메시지 헤더. 원래 메시지의 키와 값은 그대로 두고 실패 정보를 헤더로 붙인다.
+const (
+	headerError          = "x-dlq-error"
+	headerErrorClass     = "x-dlq-error-class"
+	headerAttempts       = "x-dlq-attempts"
+	headerOriginalTopic  = "x-dlq-original-topic"
+	headerOriginalPart   = "x-dlq-original-partition"
+	headerOriginalOffset = "x-dlq-original-offset"
+	headerFailedAt       = "x-dlq-failed-at"
+	headerRedriveCount   = "x-dlq-redrive-count"
+)
+
+const (
+	errorClassPermanent = "permanent"
+	errorClassTransient = "transient"
+)
+
+// errRedriveRunning은 이미 재처리가 진행 중일 때 반환됩니다.
+var errRedriveRunning = errors.New("dlq redrive already running")
+
+// permanentError는 다시 시도해도 성공할 수 없는 처리 실패입니다(디코딩 실패, 잘못된 이벤트 등).
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// isPermanent는 err를 재시도하지 않고 바로 DLQ로 보낼지 판단합니다.
+// 데이터 형식 오류(22xxx)와 제약 조건 위반(23xxx)은 같은 메시지로 다시 시도해도 실패한다.
+func isPermanent(err error) bool {
+	var perr *permanentError
+	if errors.As(err, &perr) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return strings.HasPrefix(pgErr.Code, "22") || strings.HasPrefix(pgErr.Code, "23")
+	}
+	return false
+}
+
+// retryPolicy는 일시적인 처리 실패의 재시도 정책입니다.
+type retryPolicy struct {
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+}
+
+// delay는 attempt번째 시도가 실패한 뒤 기다릴 시간입니다. backoff부터 두 배씩 늘어나 maxBackoff에서 멈춘다.
+func (p retryPolicy) delay(attempt int) time.Duration {
+	d := p.backoff
+	for i := 1; i < attempt && d < p.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.maxBackoff {
+		d = p.maxBackoff
+	}
+	return d
+}
+
+// sleepContext는 d만큼 기다립니다. 그 전에 ctx가 끝나면 false를 반환합니다.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// deadLetter는 처리하지 못한 msg를 실패 정보 헤더와 함께 DLQ 토픽에 발행합니다.
+// 발행에 실패하면 성공하거나 ctx가 끝날 때까지 재시도하며, 발행되지 않은 메시지는 커밋하지 않도록 오류를 반환한다.
+func deadLetter(ctx context.Context, logger *zap.SugaredLogger, producer *messaging.Producer, policy retryPolicy, msg kafka.Message, cause error, attempts int) error {
+	class := errorClassTransient
+	if isPermanent(cause) {
+		class = errorClassPermanent
+	}
+	headers := append(withoutDLQHeaders(msg.Headers, headerRedriveCount),
+		kafka.Header{Key: headerError, Value: []byte(cause.Error())},
+		kafka.Header{Key: headerErrorClass, Value: []byte(class)},
+		kafka.Header{Key: headerAttempts, Value: []byte(strconv.Itoa(attempts))},
+		kafka.Header{Key: headerOriginalTopic, Value: []byte(msg.Topic)},
+		kafka.Header{Key: headerOriginalPart, Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: headerOriginalOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafka.Header{Key: headerFailedAt, Value: []byte(time.Now().UTC().Format(time.RFC3339Nano))},
+	)
+	dead := messaging.Message{Key: msg.Key, Value: msg.Value, Headers: headers}
+
+	for attempt := 1; ; attempt++ {
+		err := producer.PublishBatch(ctx, []messaging.Message{dead})
+		if err == nil {
+			logger.Warnw("sent timeline event to dlq",
+				"partition", msg.Partition,
+				"offset", msg.Offset,
+				"error_class", class,
+				"attempts", attempts,
+				"error", cause,
+			)
+			return nil
+		}
+		logger.Errorw("failed to publish timeline event to dlq", "offset", msg.Offset, "attempt", attempt, "error", err)
+		if !sleepContext(ctx, policy.delay(attempt)) {
+			return ctx.Err()
+		}
+	}
+}
+
+// withoutDLQHeaders는 이전 DLQ 실패 정보를 뺀 헤더를 반환합니다. keep에 있는 DLQ 헤더는 남긴다.
+func withoutDLQHeaders(headers []kafka.Header, keep ...string) []kafka.Header {
+	out := make([]kafka.Header, 0, len(headers))
+	for _, h := range headers {
+		if strings.HasPrefix(h.Key, "x-dlq-") && !contains(keep, h.Key) {
+			continue
+		}
+		out = append(out, h)
+	}
+	return out
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// redriver는 DLQ 메시지를 원래 토픽으로 다시 넣습니다. 원인을 고친 뒤 호출해 소비자가 다시 처리하게 한다.
+type redriver struct {
+	mu       sync.Mutex
+	logger   *zap.SugaredLogger
+	consumer *messaging.Consumer
+	producer *messaging.Producer
+	wait     time.Duration
+}
+
+// newRedriver는 DLQ 토픽을 읽는 컨슈머와 원래 토픽(KAFKA_TOPIC_ACTIVITY_RAW)에 발행하는 프로듀서로 redriver를 생성합니다.
+func newRedriver(cfg config.Config, logger *zap.SugaredLogger) (*redriver, error) {
+	consumer, err := messaging.NewConsumer(messaging.ConsumerConfig{
+		Brokers: cfg.Kafka.Brokers,
+		Topic:   cfg.Timeline.DLQTopic,
+		GroupID: cfg.Timeline.DLQRedriveGroup,
+	}, logger)
+	if err != nil {
+		return nil, err
+	}
+	producer, err := messaging.NewProducer(cfg.Kafka.Brokers, cfg.Kafka.ActivityTopic, logger)
+	if err != nil {
+		_ = consumer.Close()
+		return nil, err
+	}
+	return &redriver{logger: logger, consumer: consumer, producer: producer, wait: cfg.Timeline.DLQRedriveWait}, nil
+}
+
+// Redrive는 DLQ 메시지를 최대 limit개 원래 토픽에 발행하고 커밋합니다.
+// wait 동안 새 메시지가 없으면 DLQ를 모두 비운 것으로 보고 멈춘다. 동시에 하나만 실행된다.
+func (r *redriver) Redrive(ctx context.Context, limit int) (int, error) {
+	if !r.mu.TryLock() {
+		return 0, errRedriveRunning
+	}
+	defer r.mu.Unlock()
+
+	redriven := 0
+	for redriven < limit {
+		fetchCtx, cancel := context.WithTimeout(ctx, r.wait)
+		msg, err := r.consumer.Fetch(fetchCtx)
+		drained := fetchCtx.Err() != nil && ctx.Err() == nil
+		cancel()
+		if drained {
+			break
+		}
+		if err != nil {
+			return redriven, err
+		}
+
+		count, _ := strconv.Atoi(headerValue(msg.Headers, headerRedriveCount))
+		headers := append(withoutDLQHeaders(msg.Headers),
+			kafka.Header{Key: headerRedriveCount, Value: []byte(strconv.Itoa(count + 1))},
+		)
+		if err := r.producer.PublishBatch(ctx, []messaging.Message{{Key: msg.Key, Value: msg.Value, Headers: headers}}); err != nil {
+			return redriven, fmt.Errorf("republish dlq message: %w", err)
+		}
+		if err := r.consumer.Commit(ctx, msg); err != nil {
+			// 이미 다시 발행했으므로 다음 재처리에서 중복될 수 있다. 소비자는 같은 이벤트를 다시 저장해도 결과가 같다.
+			return redriven + 1, err
+		}
+		redriven++
+		r.logger.Infow("redrove dlq message",
+			"offset", msg.Offset,
+			"original_offset", headerValue(msg.Headers, headerOriginalOffset),
+			"error", headerValue(msg.Headers, headerError),
+		)
+	}
+	return redriven, nil
+}
+
+func (r *redriver) Close() error {
+	if r == nil {
+		return nil
+	}
+	return errors.Join(r.consumer.Close(), r.producer.Close())
+}
+
+func headerValue(headers []kafka.Header, key string) string {
+	for i := len(headers) - 1; i >= 0; i-- {
+		if headers[i].Key == key {
+			return string(headers[i].Value)
+		}
+	}
+	return ""
+}
+
+// handleRedrive는 DLQ 메시지를 원래 토픽으로 다시 넣습니다. limit(기본 100, 최대 1000)개까지 처리한다.
+func (s *server) handleRedrive(w http.ResponseWriter, r *http.Request) {
+	if s.redriver == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "dlq redrive disabled"})
+		return
+	}
+
+	limit := 100
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > 1000 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be 1-1000"})
+			return
+		}
+		limit = n
+	}
+
+	redriven, err := s.redriver.Redrive(r.Context(), limit)
+	if errors.Is(err, errRedriveRunning) {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		s.logger.Errorw("failed to redrive dlq messages", "error", err, "redriven", redriven)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "failed to redrive dlq messages", "redriven": redriven})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"redriven": redriven})
+}
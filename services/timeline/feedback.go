@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"daylog/services/timeline/repository"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// maxCategoryLength는 수정 카테고리의 최대 길이(문자 수)입니다.
+	maxCategoryLength = 64
+	// maxFeedbackNoteLength는 수정 메모의 최대 길이(문자 수)입니다.
+	maxFeedbackNoteLength = 1000
+)
+
+// feedbackRequest는 타임라인 항목 카테고리 수정 요청입니다. 수정하는 사용자는 본문이 아닌 X-User-Id로 정한다.
+type feedbackRequest struct {
+	Category string `json:"category"`
+	Note     string `json:"note"`
+}
+
+// handleCreateFeedback은 사용자의 카테고리 수정을 기록하고 항목에 반영한 뒤,
+// 학습 파이프라인이 사용할 수 있도록 수정 이벤트를 발행합니다. 인증된 사용자의 항목만 수정할 수 있다.
+func (s *server) handleCreateFeedback(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authenticatedUser(w, r)
+	if !ok {
+		return
+	}
+	if _, err := uuid.Parse(userID); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "X-User-Id must be a UUID"})
+		return
+	}
+
+	timelineID := mux.Vars(r)["id"]
+	if _, err := uuid.Parse(timelineID); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "id must be a UUID"})
+		return
+	}
+
+	var req feedbackRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+	category := strings.TrimSpace(req.Category)
+	if category == "" || len([]rune(category)) > maxCategoryLength {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "category must be 1-64 characters"})
+		return
+	}
+	note := strings.TrimSpace(req.Note)
+	if len([]rune(note)) > maxFeedbackNoteLength {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "note must be at most 1000 characters"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	feedback, err := s.repo.RecordFeedback(ctx, repository.Feedback{
+		FeedbackID:  uuid.NewString(),
+		TimelineID:  timelineID,
+		UserID:      userID,
+		NewCategory: category,
+		Note:        note,
+	})
+	if errors.Is(err, repository.ErrEntryNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "timeline entry not found"})
+		return
+	}
+	if err != nil {
+		s.logger.Errorw("failed to record activity feedback", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to record feedback"})
+		return
+	}
+
+	// 수정은 이미 저장되었으므로 발행에 실패해도 요청은 성공으로 응답한다.
+	if s.producer != nil {
+		bytes, _ := json.Marshal(feedback)
+		if err := s.producer.Publish(ctx, []byte(feedback.UserID), bytes); err != nil {
+			s.logger.Warnw("failed to publish activity feedback event", "error", err, "feedback_id", feedback.FeedbackID)
+		}
+	}
+
+	writeJSON(w, http.StatusCreated, feedback)
+}
@@ -0,0 +1,137 @@
+// Package geofence는 사용자 장소(원 또는 다각형)의 포함 여부를 판정합니다.
+package geofence
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// 장소 모양
+const (
+	ShapeCircle  = "circle"
+	ShapePolygon = "polygon"
+)
+
+// 장소 크기 제한. 너무 작은 원은 GPS 오차로 방문이 끊기고, 너무 큰 장소는 의미가 없다.
+const (
+	MinRadiusM      = 10.0
+	MaxRadiusM      = 50000.0
+	MaxPolygonSides = 100
+)
+
+const earthRadiusM = 6371008.8
+
+// Point는 WGS84 좌표입니다.
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// Shape는 원(Center, RadiusM) 또는 다각형(Polygon) 장소 경계입니다.
+type Shape struct {
+	Kind    string
+	Center  Point
+	RadiusM float64
+	Polygon []Point
+}
+
+// Validate는 경계가 올바른지 확인합니다. 다각형은 닫는 꼭짓점을 반복하지 않아도 된다.
+func (s Shape) Validate() error {
+	switch s.Kind {
+	case ShapeCircle:
+		if err := s.Center.validate(); err != nil {
+			return fmt.Errorf("center: %w", err)
+		}
+		if s.RadiusM < MinRadiusM || s.RadiusM > MaxRadiusM {
+			return fmt.Errorf("radius_m must be between %.0f and %.0f", MinRadiusM, MaxRadiusM)
+		}
+	case ShapePolygon:
+		if len(s.Polygon) < 3 || len(s.Polygon) > MaxPolygonSides {
+			return fmt.Errorf("polygon must have between 3 and %d vertices", MaxPolygonSides)
+		}
+		for i, p := range s.Polygon {
+			if err := p.validate(); err != nil {
+				return fmt.Errorf("polygon[%d]: %w", i, err)
+			}
+		}
+	default:
+		return fmt.Errorf("shape must be %q or %q", ShapeCircle, ShapePolygon)
+	}
+	return nil
+}
+
+// Contains는 p가 경계 안에 있는지 확인합니다.
+func (s Shape) Contains(p Point) bool {
+	switch s.Kind {
+	case ShapeCircle:
+		return Distance(s.Center, p) <= s.RadiusM
+	case ShapePolygon:
+		return polygonContains(s.Polygon, p)
+	}
+	return false
+}
+
+// Area는 경계의 대략적인 면적(m²)입니다. 여러 장소가 겹칠 때 더 좁은 장소를 고르는 데 사용한다.
+func (s Shape) Area() float64 {
+	switch s.Kind {
+	case ShapeCircle:
+		return math.Pi * s.RadiusM * s.RadiusM
+	case ShapePolygon:
+		return polygonArea(s.Polygon)
+	}
+	return 0
+}
+
+// Distance는 두 좌표 사이의 대원 거리(m)입니다.
+func Distance(a, b Point) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat := lat2 - lat1
+	dLng := radians(b.Lng - a.Lng)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusM * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+var errOutOfRange = errors.New("lat must be within [-90, 90] and lng within [-180, 180]")
+
+func (p Point) validate() error {
+	if math.IsNaN(p.Lat) || math.IsNaN(p.Lng) || p.Lat < -90 || p.Lat > 90 || p.Lng < -180 || p.Lng > 180 {
+		return errOutOfRange
+	}
+	return nil
+}
+
+// polygonContains는 ray casting으로 포함 여부를 판정합니다.
+// 사용자 장소는 수 km 이내이므로 위경도를 평면 좌표로 취급하며, 날짜 변경선을 가로지르는 다각형은 지원하지 않는다.
+func polygonContains(polygon []Point, p Point) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lng < (b.Lng-a.Lng)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// polygonArea는 첫 꼭짓점 기준 등장방형 투영으로 계산한 다각형 면적입니다.
+func polygonArea(polygon []Point) float64 {
+	if len(polygon) < 3 {
+		return 0
+	}
+	origin := polygon[0]
+	scale := math.Cos(radians(origin.Lat))
+	var sum float64
+	for i := range polygon {
+		a, b := polygon[i], polygon[(i+1)%len(polygon)]
+		ax, ay := radians(a.Lng-origin.Lng)*scale, radians(a.Lat-origin.Lat)
+		bx, by := radians(b.Lng-origin.Lng)*scale, radians(b.Lat-origin.Lat)
+		sum += ax*by - bx*ay
+	}
+	return math.Abs(sum) / 2 * earthRadiusM * earthRadiusM
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
@@ -4,16 +4,22 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"os/signal"
 	"strconv"
 	"syscall"
 	"time"
+	_ "time/tzdata" // 런타임 이미지(alpine)에 시간대 데이터가 없어도 사용자 시간대를 해석할 수 있도록 내장한다.
 
 	"daylog/services/common/config"
 	"daylog/services/common/db"
+	"daylog/services/common/fieldcrypt"
 	"daylog/services/common/logging"
 	"daylog/services/common/messaging"
+	"daylog/services/timeline/classifier"
+	"daylog/services/timeline/dedup"
+	"daylog/services/timeline/merge"
 	"daylog/services/timeline/repository"
 
 	"github.com/gorilla/mux"
@@ -21,11 +27,16 @@ import (
 )
 
 type server struct {
-	cfg      config.Config
-	logger   *zap.SugaredLogger
-	repo     *repository.Repository
-	consumer *messaging.Consumer
-	router   *mux.Router
+	cfg             config.Config
+	logger          *zap.SugaredLogger
+	repo            *repository.Repository
+	consumer        *messaging.Consumer
+	producer        *messaging.Producer
+	redriver        *redriver
+	router          *mux.Router
+	defaultLocation *time.Location
+	// internal은 외부에 노출하지 않는 내부 리스너의 운영 API와 사용자 API 라우터입니다.
+	internal *mux.Router
 }
 
 type activityEvent struct {
@@ -35,6 +46,7 @@ type activityEvent struct {
 	StartedAt time.Time              `json:"started_at"`
 	EndedAt   time.Time              `json:"ended_at"`
 	Metadata  map[string]interface{} `json:"metadata"`
+	DeviceID  string                 `json:"device_id,omitempty"`
 }
 
 func main() {
@@ -59,10 +71,116 @@ func main() {
 	}
 	defer pool.Close()
 
-	repo := repository.New(pool)
+	var crypt *fieldcrypt.Encryptor
+	if cfg.HasEncryption() {
+		masterKey, err := fieldcrypt.LoadMasterKey(cfg.Encryption.MasterKeyFile)
+		if err != nil {
+			logger.Fatalw("failed to load encryption master key", "error", err)
+		}
+		keys, err := fieldcrypt.NewKeyStore(pool, masterKey, cfg.Encryption.KeyCacheTTL)
+		if err != nil {
+			logger.Fatalw("failed to initialise data key store", "error", err)
+		}
+		crypt = fieldcrypt.New(keys)
+	}
+
+	repo := repository.New(pool, crypt)
+
+	defaultLocation, err := time.LoadLocation(cfg.Timeline.DefaultTimezone)
+	if err != nil {
+		logger.Fatalw("invalid default timezone", "timezone", cfg.Timeline.DefaultTimezone, "error", err)
+	}
+
+	var deduper *dedup.Deduplicator
+	if cfg.Timeline.DedupEnabled {
+		deduper, err = dedup.New(dedup.Config{
+			OverlapRatio:   cfg.Timeline.DedupOverlapRatio,
+			Kinds:          cfg.Timeline.DedupKinds,
+			SourcePriority: cfg.Timeline.DedupSourcePriority,
+		})
+		if err != nil {
+			logger.Fatalw("invalid deduplication settings", "error", err)
+		}
+	}
+
+	merger, err := merge.New(merge.Config{
+		Gap:             cfg.Timeline.MergeGap,
+		Window:          cfg.Timeline.MergeWindow,
+		LocationRadiusM: cfg.Timeline.MergeLocationRadiusM,
+	}, deduper)
+	if err != nil {
+		logger.Fatalw("invalid merge settings", "error", err)
+	}
+
+	var classify *classifier.Client
+	if cfg.Timeline.ClassifierURL != "" {
+		classify, err = classifier.New(classifier.Config{
+			URL:              cfg.Timeline.ClassifierURL,
+			Timeout:          cfg.Timeline.ClassifierTimeout,
+			Concurrency:      cfg.Timeline.ClassifierConcurrency,
+			FailureThreshold: cfg.Timeline.ClassifierFailureThreshold,
+			Cooldown:         cfg.Timeline.ClassifierCooldown,
+		})
+		if err != nil {
+			logger.Fatalw("invalid classifier settings", "error", err)
+		}
+	} else {
+		logger.Warn("activity classifier disabled: TIMELINE_CLASSIFIER_URL not set")
+	}
+
+	policy := retryPolicy{
+		maxAttempts: cfg.Timeline.ConsumerMaxAttempts,
+		backoff:     cfg.Timeline.ConsumerRetryBackoff,
+		maxBackoff:  cfg.Timeline.ConsumerMaxBackoff,
+	}
+	if policy.maxAttempts <= 0 || policy.backoff <= 0 || policy.maxBackoff < policy.backoff {
+		logger.Fatalw("invalid consumer retry settings",
+			"max_attempts", policy.maxAttempts,
+			"backoff", policy.backoff,
+			"max_backoff", policy.maxBackoff,
+		)
+	}
+
+	if cfg.Timeline.ConsumerWorkers <= 0 || cfg.Timeline.ConsumerBatchSize <= 0 || cfg.Timeline.ConsumerCommitInterval <= 0 || cfg.Timeline.ConsumerDrainTimeout <= 0 {
+		logger.Fatalw("invalid consumer settings",
+			"workers", cfg.Timeline.ConsumerWorkers,
+			"batch_size", cfg.Timeline.ConsumerBatchSize,
+			"commit_interval", cfg.Timeline.ConsumerCommitInterval,
+			"drain_timeout", cfg.Timeline.ConsumerDrainTimeout,
+		)
+	}
 
-	var consumer *messaging.Consumer
+	processor := &eventProcessor{
+		logger:     logger,
+		repo:       repo,
+		merger:     merger,
+		classifier: classify,
+		visitGap:   cfg.Timeline.GeofenceVisitGap,
+	}
+
+	var (
+		consumer     *messaging.Consumer
+		consumerDone = make(chan struct{})
+		purges       *messaging.Consumer
+		purgesDone   = make(chan struct{})
+		deadLetters  *messaging.Producer
+		redrive      *redriver
+	)
 	if cfg.HasKafka() {
+		deadLetters, err = messaging.NewProducer(cfg.Kafka.Brokers, cfg.Timeline.DLQTopic, logger)
+		if err != nil {
+			logger.Errorw("failed to create dlq producer", "error", err)
+		} else {
+			defer deadLetters.Close()
+		}
+
+		redrive, err = newRedriver(cfg, logger)
+		if err != nil {
+			logger.Errorw("failed to initialise dlq redrive", "error", err)
+		} else {
+			defer redrive.Close()
+		}
+
 		consumer, err = messaging.NewConsumer(messaging.ConsumerConfig{
 			Brokers: cfg.Kafka.Brokers,
 			Topic:   cfg.Kafka.ActivityTopic,
@@ -71,13 +189,62 @@ func main() {
 		if err != nil {
 			logger.Errorw("failed to initialise kafka consumer", "error", err)
 		} else {
-			go startConsumerLoop(ctx, logger, consumer, repo)
+			pool := &consumerPool{
+				logger:         logger,
+				consumer:       consumer,
+				crypt:          crypt,
+				processor:      processor,
+				deadLetters:    deadLetters,
+				policy:         policy,
+				workers:        cfg.Timeline.ConsumerWorkers,
+				batchSize:      cfg.Timeline.ConsumerBatchSize,
+				commitInterval: cfg.Timeline.ConsumerCommitInterval,
+				drainTimeout:   cfg.Timeline.ConsumerDrainTimeout,
+				offsets:        newOffsetTracker(),
+			}
+			go func() {
+				defer close(consumerDone)
+				pool.Run(ctx)
+			}()
+		}
+
+		purges, err = messaging.NewConsumer(messaging.ConsumerConfig{
+			Brokers: cfg.Kafka.Brokers,
+			Topic:   cfg.Kafka.PurgeTopic,
+			GroupID: cfg.Timeline.PurgeGroup,
+		}, logger)
+		if err != nil {
+			logger.Errorw("failed to initialise kafka purge consumer", "error", err)
+		} else {
+			purger := &purgeConsumer{
+				logger:    logger,
+				consumer:  purges,
+				processor: processor,
+				policy:    policy,
+			}
+			go func() {
+				defer close(purgesDone)
+				purger.Run(ctx)
+			}()
 		}
 	} else {
 		logger.Warn("timeline consumer disabled: KAFKA_BROKERS not set")
 	}
 
-	srv := newServer(cfg, logger, repo, consumer)
+	var producer *messaging.Producer
+	if cfg.HasKafka() {
+		producer, err = messaging.NewProducer(cfg.Kafka.Brokers, cfg.Timeline.FeedbackTopic, logger)
+		if err != nil {
+			logger.Errorw("failed to create kafka producer", "error", err)
+		}
+		defer func() {
+			if producer != nil {
+				_ = producer.Close()
+			}
+		}()
+	}
+
+	srv := newServer(cfg, logger, repo, consumer, producer, redrive, defaultLocation)
 
 	httpServer := &http.Server{
 		Addr:              cfg.Addr(),
@@ -85,6 +252,23 @@ func main() {
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
+	var internalServer *http.Server
+	if cfg.Timeline.InternalPort != "" {
+		internalServer = &http.Server{
+			Addr:              ":" + cfg.Timeline.InternalPort,
+			Handler:           srv.internal,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		go func() {
+			logger.Infow("timeline internal server listening", "addr", internalServer.Addr)
+			if err := internalServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				logger.Fatalw("internal http server error", "error", err)
+			}
+		}()
+	} else {
+		logger.Warn("TIMELINE_INTERNAL_PORT not set, dlq redrive, place management and feedback API disabled")
+	}
+
 	go func() {
 		<-ctx.Done()
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -92,8 +276,10 @@ func main() {
 		if err := httpServer.Shutdown(shutdownCtx); err != nil {
 			logger.Errorw("failed to shutdown http server", "error", err)
 		}
-		if consumer != nil {
-			_ = consumer.Close()
+		if internalServer != nil {
+			if err := internalServer.Shutdown(shutdownCtx); err != nil {
+				logger.Errorw("failed to shutdown internal http server", "error", err)
+			}
 		}
 	}()
 
@@ -101,21 +287,45 @@ func main() {
 	if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
 		logger.Fatalw("http server error", "error", err)
 	}
+
+	// 가져온 메시지를 마저 처리하고 오프셋을 커밋하도록 기다린다.
+	if consumer != nil {
+		<-consumerDone
+		_ = consumer.Close()
+	}
+	if purges != nil {
+		<-purgesDone
+		_ = purges.Close()
+	}
 }
 
-func newServer(cfg config.Config, logger *zap.SugaredLogger, repo *repository.Repository, consumer *messaging.Consumer) *server {
+func newServer(cfg config.Config, logger *zap.SugaredLogger, repo *repository.Repository, consumer *messaging.Consumer, producer *messaging.Producer, redrive *redriver, defaultLocation *time.Location) *server {
 	s := &server{
-		cfg:      cfg,
-		logger:   logger,
-		repo:     repo,
-		consumer: consumer,
-		router:   mux.NewRouter(),
+		cfg:             cfg,
+		logger:          logger,
+		repo:            repo,
+		consumer:        consumer,
+		producer:        producer,
+		redriver:        redrive,
+		router:          mux.NewRouter(),
+		internal:        mux.NewRouter(),
+		defaultLocation: defaultLocation,
 	}
 
 	s.router.Use(s.loggingMiddleware)
 	s.router.HandleFunc("/healthz", s.handleHealth).Methods(http.MethodGet)
 	s.router.HandleFunc("/readyz", s.handleReady).Methods(http.MethodGet)
 	s.router.HandleFunc("/v1/timeline/{userId}", s.handleGetTimeline).Methods(http.MethodGet)
+	s.router.HandleFunc("/v1/timeline/{userId}/days/{date}", s.handleGetDay).Methods(http.MethodGet)
+
+	s.internal.Use(s.loggingMiddleware)
+	s.internal.HandleFunc("/healthz", s.handleHealth).Methods(http.MethodGet)
+	s.internal.HandleFunc("/v1/timeline/dlq/redrive", s.handleRedrive).Methods(http.MethodPost)
+	s.internal.HandleFunc("/v1/timeline/entries/{id}/feedback", s.handleCreateFeedback).Methods(http.MethodPost)
+	s.internal.HandleFunc("/v1/places/{userId}", requireUser(s.handleCreatePlace)).Methods(http.MethodPost)
+	s.internal.HandleFunc("/v1/places/{userId}", requireUser(s.handleListPlaces)).Methods(http.MethodGet)
+	s.internal.HandleFunc("/v1/places/{userId}/{placeId}", requireUser(s.handleUpdatePlace)).Methods(http.MethodPut)
+	s.internal.HandleFunc("/v1/places/{userId}/{placeId}", requireUser(s.handleDeletePlace)).Methods(http.MethodDelete)
 
 	return s
 }
@@ -145,6 +355,12 @@ func (s *server) handleReady(w http.ResponseWriter, r *http.Request) {
 		status["kafka"] = "disabled"
 	}
 
+	if s.producer != nil {
+		status["kafka_producer"] = "ok"
+	} else {
+		status["kafka_producer"] = "disabled"
+	}
+
 	writeJSON(w, http.StatusOK, map[string]any{
 		"status":  "ok",
 		"service": s.cfg.Service.Name,
@@ -153,10 +369,15 @@ func (s *server) handleReady(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// timelinePage는 타임라인 조회 응답입니다. NextCursor가 비어 있으면 마지막 페이지다.
+type timelinePage struct {
+	Entries    []repository.Block `json:"entries"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
 func (s *server) handleGetTimeline(w http.ResponseWriter, r *http.Request) {
-	userID := mux.Vars(r)["userId"]
-	if userID == "" {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "userId is required"})
+	userID, ok := pathUserID(w, r)
+	if !ok {
 		return
 	}
 
@@ -167,17 +388,31 @@ func (s *server) handleGetTimeline(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	q, err := timelineQuery(r.URL.Query())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	// 다음 페이지가 있는지 알기 위해 한 건 더 조회한다.
+	q.Limit = limit + 1
+
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	entries, err := s.repo.ListActivityEvents(ctx, userID, limit)
+	entries, err := s.repo.ListTimeline(ctx, userID, q)
 	if err != nil {
 		s.logger.Errorw("failed to fetch timeline", "error", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch timeline"})
 		return
 	}
 
-	writeJSON(w, http.StatusOK, entries)
+	page := timelinePage{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		last := page.Entries[limit-1]
+		page.NextCursor = encodeCursor(repository.TimelineCursor{StartedAt: last.StartedAt, TimelineID: last.TimelineID})
+	}
+	writeJSON(w, http.StatusOK, page)
 }
 
 func (s *server) loggingMiddleware(next http.Handler) http.Handler {
@@ -192,55 +427,124 @@ func (s *server) loggingMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-func startConsumerLoop(ctx context.Context, logger *zap.SugaredLogger, consumer *messaging.Consumer, repo *repository.Repository) {
-	logger.Infow("starting timeline consumer loop")
-	for {
-		select {
-		case <-ctx.Done():
-			logger.Infow("timeline consumer context cancelled")
-			return
-		default:
-		}
+// eventProcessor는 소비한 이벤트를 타임라인 블록으로 병합해 저장합니다.
+type eventProcessor struct {
+	logger     *zap.SugaredLogger
+	repo       *repository.Repository
+	merger     *merge.Merger
+	classifier *classifier.Client
+	visitGap   time.Duration
+}
 
-		msg, err := consumer.Fetch(ctx)
-		if err != nil {
-			if errors.Is(err, context.Canceled) {
-				logger.Infow("timeline consumer stopped")
-				return
-			}
-			logger.Errorw("failed to fetch kafka message", "error", err)
-			time.Sleep(time.Second)
-			continue
-		}
+// store는 evt가 속할 블록을 주변 이벤트와 함께 다시 계산해 저장합니다.
+// 늦게 도착한 이벤트가 두 블록을 잇거나 블록의 첫 이벤트를 바꾸면 기존 항목은 새 블록 항목으로 대체된다.
+func (p *eventProcessor) store(ctx context.Context, evt repository.Entry) error {
+	entry, err := p.prepare(ctx, evt)
+	if err != nil {
+		return err
+	}
+	return p.repo.MergeEntries(ctx, entry)
+}
 
-		var evt activityEvent
-		if err := json.Unmarshal(msg.Value, &evt); err != nil {
-			logger.Errorw("failed to decode kafka message", "error", err)
-			_ = consumer.Commit(ctx, msg)
-			continue
-		}
+// prepare는 evt가 속할 블록을 주변 이벤트와 함께 다시 계산하고,
+// 위치 블록이라면 사용자 장소 방문 정보를 geo_context에 채운 뒤 분류합니다. 블록 항목은 저장하지 않는다.
+func (p *eventProcessor) prepare(ctx context.Context, evt repository.Entry) (repository.Entry, error) {
+	events, err := p.blockEvents(ctx, evt)
+	if err != nil {
+		return repository.Entry{}, err
+	}
 
-		entry := repository.Entry{
-			EventID:      evt.EventID,
-			UserID:       evt.UserID,
-			Source:       evt.Source,
-			Category:     evt.Source,
-			StartedAt:    evt.StartedAt,
-			EndedAt:      evt.EndedAt,
-			Metadata:     evt.Metadata,
-			GeoContext:   map[string]any{},
-			Confidence:   0.6,
-			SourceEvents: []string{evt.EventID},
-		}
+	entry, ok := merge.Find(p.merger.Merge(events), evt.EventID)
+	if !ok {
+		return repository.Entry{}, permanent(fmt.Errorf("event %s missing from merged blocks", evt.EventID))
+	}
+	// 클라이언트가 보낸 geo_context가 있으면 유지하고, 사용자 장소 안이면 방문 정보로 바꾼다.
+	entry.GeoContext = map[string]any{}
+	if geo, ok := entry.Metadata["geo_context"].(map[string]any); ok {
+		entry.GeoContext = geo
+	}
+	if err := p.tagPlace(ctx, &entry); err != nil {
+		return repository.Entry{}, err
+	}
+	p.classify(ctx, &entry)
+
+	if len(entry.SourceEvents) > 1 {
+		p.logger.Debugw("merged timeline block",
+			"user_id", entry.UserID,
+			"timeline_id", entry.EventID,
+			"category", entry.Category,
+			"events", len(entry.SourceEvents),
+			"confidence", entry.Confidence,
+		)
+	}
+	return entry, nil
+}
+
+// classify는 블록을 활동 분류 서비스로 분류해 카테고리, 분류 신뢰도, 근거, 모델 버전을 채웁니다.
+// 분류 서비스가 없거나 실패하면 병합 단계의 규칙 기반 카테고리를 유지하고 model_version을 RuleModelVersion으로 남긴다.
+func (p *eventProcessor) classify(ctx context.Context, entry *repository.Entry) {
+	entry.ModelVersion = repository.RuleModelVersion
+	if p.classifier == nil {
+		return
+	}
 
-		if err := repo.UpsertTimelineEntry(ctx, entry); err != nil {
-			logger.Errorw("failed to upsert timeline entry", "error", err)
+	result, err := p.classifier.Classify(ctx, *entry)
+	if err != nil {
+		if !errors.Is(err, classifier.ErrCircuitOpen) {
+			p.logger.Warnw("failed to classify timeline block, using rule-based category",
+				"timeline_id", entry.EventID,
+				"error", err,
+			)
 		}
+		return
+	}
 
-		if err := consumer.Commit(ctx, msg); err != nil {
-			logger.Errorw("failed to commit kafka message", "error", err)
+	entry.Category = result.Category
+	entry.CategoryConfidence = result.Confidence
+	entry.Rationale = result.Rationale
+	entry.ModelVersion = result.ModelVersion
+	if entry.ModelVersion == "" {
+		entry.ModelVersion = "unknown"
+	}
+}
+
+// blockEvents는 evt와 같은 블록에 들어갈 수 있는 이벤트를 모읍니다.
+// 병합 범위 안의 이벤트와, 그 이벤트를 이미 포함한 기존 블록의 나머지 이벤트를 함께 반환한다.
+func (p *eventProcessor) blockEvents(ctx context.Context, evt repository.Entry) ([]repository.Entry, error) {
+	from, start, end := p.merger.Range(evt)
+	events, err := p.repo.OverlappingEvents(ctx, evt.UserID, from, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(events)+1)
+	found := false
+	for i, e := range events {
+		if e.EventID == evt.EventID {
+			// 메시지의 메타데이터는 이미 복호화되어 있고, 저장된 값과 같다.
+			events[i], found = evt, true
 		}
+		ids = append(ids, e.EventID)
+	}
+	if !found {
+		// 동의 철회로 삭제된 소스의 이벤트는 삭제 전에 발행되어 늦게 도착한 것이므로 항목을 다시 만들지 않는다.
+		purged, err := p.repo.SourcePurged(ctx, evt.UserID, evt.Source)
+		if err != nil {
+			return nil, err
+		}
+		if purged {
+			return nil, permanent(errEventPurged)
+		}
+		// 재발행 토픽 등 activity_events에 아직 없는 이벤트도 처리한다.
+		events = append(events, evt)
+		ids = append(ids, evt.EventID)
+	}
+
+	related, err := p.repo.RelatedEvents(ctx, evt.UserID, ids)
+	if err != nil {
+		return nil, err
 	}
+	return append(events, related...), nil
 }
 
 func writeJSON(w http.ResponseWriter, status int, data interface{}) {
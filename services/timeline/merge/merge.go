@@ -0,0 +1,242 @@
+// Package merge는 사용자의 이벤트 중 겹치거나 가까이 이어진 같은 카테고리 구간을 하나의 타임라인 블록으로 합칩니다.
+// 여러 기기가 보고한 같은 활동은 dedup으로 먼저 대표 이벤트 하나로 접고, 대표 이벤트들의 구간을 이어 붙인다.
+// 블록의 신뢰도는 기여한 소스들로부터 계산하며, 기여한 모든 event_id를 source_event_ids에 남긴다.
+package merge
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"daylog/services/timeline/dedup"
+	"daylog/services/timeline/geofence"
+	"daylog/services/timeline/repository"
+)
+
+// defaultConfidence는 메타데이터에 confidence가 없는 이벤트의 신뢰도입니다.
+const defaultConfidence = 0.6
+
+// maxConfidence는 여러 소스가 합쳐진 블록 신뢰도의 상한입니다. 소스가 많아도 확정으로 보지 않는다.
+const maxConfidence = 0.99
+
+// Config는 블록 병합 설정입니다.
+type Config struct {
+	// Gap은 같은 블록으로 이을 두 구간 사이의 최대 간격입니다. 0이면 겹치거나 맞닿은 구간만 합친다.
+	Gap time.Duration
+	// Window는 이벤트 하나를 처리할 때 그보다 먼저 시작한 이벤트를 조회하는 범위입니다.
+	Window time.Duration
+	// LocationRadiusM은 같은 블록으로 이을 위치 이벤트 좌표 사이의 최대 거리(m)입니다.
+	LocationRadiusM float64
+}
+
+// Merger는 Config에 따라 이벤트를 블록으로 합칩니다.
+type Merger struct {
+	gap     time.Duration
+	window  time.Duration
+	radius  float64
+	deduper *dedup.Deduplicator
+}
+
+// New는 Merger를 생성합니다. deduper가 nil이면 기기 간 중복을 접지 않고 구간만 합칩니다.
+func New(cfg Config, deduper *dedup.Deduplicator) (*Merger, error) {
+	if cfg.Gap < 0 {
+		return nil, fmt.Errorf("merge gap must not be negative, got %s", cfg.Gap)
+	}
+	if cfg.Window <= 0 {
+		return nil, fmt.Errorf("merge window must be positive, got %s", cfg.Window)
+	}
+	if cfg.LocationRadiusM <= 0 {
+		return nil, fmt.Errorf("merge location radius must be positive, got %v", cfg.LocationRadiusM)
+	}
+	return &Merger{gap: cfg.Gap, window: cfg.Window, radius: cfg.LocationRadiusM, deduper: deduper}, nil
+}
+
+// Range는 evt가 속할 블록을 다시 계산할 때 조회할 이벤트 범위를 반환합니다.
+// [start, end] 구간과 겹치고 from 이후에 시작한 이벤트가 evt와 직접 이어질 수 있는 이벤트다.
+func (m *Merger) Range(evt repository.Entry) (from, start, end time.Time) {
+	return evt.StartedAt.Add(-m.window), evt.StartedAt.Add(-m.gap), evt.EndedAt.Add(m.gap)
+}
+
+// block은 병합 중인 블록입니다. anchor는 블록에서 가장 먼저 시작한 대표 이벤트다.
+type block struct {
+	anchor  repository.Entry
+	members []repository.Entry
+	events  []repository.Entry
+	end     time.Time
+}
+
+// Merge는 events를 블록으로 합쳐 타임라인 항목 목록을 반환합니다. 결과는 시작 시각 순이다.
+// 항목의 EventID(timeline_id)는 블록에서 가장 먼저 시작한 이벤트의 ID이고, Source와 Metadata는 블록의 대표 이벤트 값이다.
+// 같은 이벤트 집합이면 입력 순서와 관계없이 같은 결과를 내므로, 늦게 도착한 이벤트가 있어도 다시 병합하면 된다.
+func (m *Merger) Merge(events []repository.Entry) []repository.Entry {
+	canonical := events
+	var suppressed map[string][]repository.Entry
+	if m.deduper != nil {
+		canonical, suppressed = m.deduper.Resolve(events)
+	}
+	canonical = append([]repository.Entry(nil), canonical...)
+	sort.SliceStable(canonical, func(i, j int) bool { return startsBefore(canonical[i], canonical[j]) })
+
+	var (
+		blocks []*block
+		open   []*block
+	)
+	for _, evt := range canonical {
+		var target *block
+		for _, b := range open {
+			if !evt.StartedAt.After(b.end.Add(m.gap)) && m.compatible(b.anchor, evt) {
+				target = b
+				break
+			}
+		}
+		if target == nil {
+			target = &block{anchor: evt, end: evt.EndedAt}
+			blocks = append(blocks, target)
+			open = append(open, target)
+		}
+		target.members = append(target.members, evt)
+		target.events = append(target.events, evt)
+		target.events = append(target.events, suppressed[evt.EventID]...)
+		if evt.EndedAt.After(target.end) {
+			target.end = evt.EndedAt
+		}
+
+		// 시작 시각 순으로 처리하므로, 간격을 넘어선 블록은 이후 이벤트와 이어질 수 없다.
+		kept := open[:0]
+		for _, b := range open {
+			if !evt.StartedAt.After(b.end.Add(m.gap)) {
+				kept = append(kept, b)
+			}
+		}
+		open = kept
+	}
+
+	entries := make([]repository.Entry, 0, len(blocks))
+	for _, b := range blocks {
+		entries = append(entries, m.entry(b))
+	}
+	return entries
+}
+
+// Find는 blocks 중 eventID를 포함하는 항목을 찾습니다.
+func Find(blocks []repository.Entry, eventID string) (repository.Entry, bool) {
+	for _, b := range blocks {
+		for _, id := range b.SourceEvents {
+			if id == eventID {
+				return b, true
+			}
+		}
+	}
+	return repository.Entry{}, false
+}
+
+func (m *Merger) entry(b *block) repository.Entry {
+	representative := b.members[0]
+	for _, evt := range b.members[1:] {
+		if m.preferred(evt, representative) {
+			representative = evt
+		}
+	}
+
+	sort.SliceStable(b.events, func(i, j int) bool { return startsBefore(b.events[i], b.events[j]) })
+	ids := make([]string, 0, len(b.events))
+	for _, evt := range b.events {
+		ids = append(ids, evt.EventID)
+	}
+
+	entry := representative
+	entry.EventID = b.anchor.EventID
+	entry.StartedAt = b.anchor.StartedAt
+	entry.EndedAt = b.end
+	entry.Category = Category(b.anchor)
+	entry.Confidence = confidence(b.events)
+	entry.SourceEvents = ids
+	return entry
+}
+
+// Category는 블록을 나누는 카테고리입니다. metadata.category가 있으면 그 값을, 없으면 소스 종류를 사용한다.
+func Category(evt repository.Entry) string {
+	if category, ok := evt.Metadata["category"].(string); ok && category != "" {
+		return category
+	}
+	if kind := dedup.SourceKind(evt.Source); kind != "" {
+		return kind
+	}
+	return evt.Source
+}
+
+// compatible은 evt를 anchor가 시작한 블록에 이을 수 있는지 확인합니다.
+// 카테고리가 같아야 하며, 건강 데이터는 type(workout, sleep 등)이 같아야 하고,
+// 위치 이벤트는 블록 첫 좌표에서 LocationRadiusM 안에 있어야 한다(조금씩 움직여 블록이 끝없이 늘어나지 않도록).
+func (m *Merger) compatible(anchor, evt repository.Entry) bool {
+	if Category(anchor) != Category(evt) {
+		return false
+	}
+	switch dedup.SourceKind(evt.Source) {
+	case "health":
+		return metaString(anchor, "type") == metaString(evt, "type")
+	case "location":
+		a, aOK := point(anchor)
+		b, bOK := point(evt)
+		if aOK && bOK {
+			return geofence.Distance(a, b) <= m.radius
+		}
+	}
+	return true
+}
+
+// preferred는 블록의 대표 이벤트 선택 순서입니다. deduper가 있으면 그 소스 우선순위를 따른다.
+func (m *Merger) preferred(a, b repository.Entry) bool {
+	if m.deduper != nil {
+		return m.deduper.Preferred(a, b)
+	}
+	if la, lb := a.EndedAt.Sub(a.StartedAt), b.EndedAt.Sub(b.StartedAt); la != lb {
+		return la > lb
+	}
+	return startsBefore(a, b)
+}
+
+// confidence는 소스별 최고 신뢰도를 독립된 근거로 보고 합친 블록 신뢰도(1 - Π(1 - c))입니다.
+// 같은 소스의 조각은 신뢰도를 높이지 않고, 서로 다른 소스가 같은 활동을 보고할수록 높아진다.
+func confidence(events []repository.Entry) float64 {
+	best := map[string]float64{}
+	for _, evt := range events {
+		c := defaultConfidence
+		if v, ok := evt.Metadata["confidence"].(float64); ok && v > 0 && v <= 1 {
+			c = v
+		}
+		if c > best[evt.Source] {
+			best[evt.Source] = c
+		}
+	}
+
+	if len(best) == 1 {
+		for _, c := range best {
+			return c
+		}
+	}
+	miss := 1.0
+	for _, c := range best {
+		miss *= 1 - c
+	}
+	return math.Round(math.Min(1-miss, maxConfidence)*1e4) / 1e4
+}
+
+func startsBefore(a, b repository.Entry) bool {
+	if !a.StartedAt.Equal(b.StartedAt) {
+		return a.StartedAt.Before(b.StartedAt)
+	}
+	return a.EventID < b.EventID
+}
+
+func point(evt repository.Entry) (geofence.Point, bool) {
+	lat, latOK := evt.Metadata["latitude"].(float64)
+	lng, lngOK := evt.Metadata["longitude"].(float64)
+	return geofence.Point{Lat: lat, Lng: lng}, latOK && lngOK
+}
+
+func metaString(e repository.Entry, key string) string {
+	v, _ := e.Metadata[key].(string)
+	return v
+}
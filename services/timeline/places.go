@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"daylog/services/timeline/geofence"
+	"daylog/services/timeline/repository"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// maxPlaceNameLength는 장소 이름의 최대 길이(문자 수)입니다.
+const maxPlaceNameLength = 64
+
+// placeRequest는 장소 생성·수정 요청입니다. shape가 circle이면 center와 radius_m, polygon이면 polygon을 사용합니다.
+type placeRequest struct {
+	Name    string           `json:"name"`
+	Shape   string           `json:"shape"`
+	Center  *geofence.Point  `json:"center"`
+	RadiusM float64          `json:"radius_m"`
+	Polygon []geofence.Point `json:"polygon"`
+}
+
+// place는 요청을 검증해 저장용 장소로 변환합니다.
+func (req placeRequest) place(userID, placeID string) (repository.Place, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len([]rune(name)) > maxPlaceNameLength {
+		return repository.Place{}, errors.New("name must be 1-64 characters")
+	}
+
+	place := repository.Place{PlaceID: placeID, UserID: userID, Name: name, Shape: req.Shape}
+	switch req.Shape {
+	case geofence.ShapeCircle:
+		if req.Center == nil {
+			return repository.Place{}, errors.New("center is required for circle places")
+		}
+		place.Center, place.RadiusM = req.Center, req.RadiusM
+	case geofence.ShapePolygon:
+		place.Polygon = req.Polygon
+	}
+	if err := place.Boundary().Validate(); err != nil {
+		return repository.Place{}, err
+	}
+	return place, nil
+}
+
+func (s *server) handleCreatePlace(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+	req, ok := decodePlaceRequest(w, r)
+	if !ok {
+		return
+	}
+	place, err := req.place(userID, uuid.NewString())
+	if err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	created, err := s.repo.CreatePlace(ctx, place)
+	if err != nil {
+		s.writePlaceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+func (s *server) handleListPlaces(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	places, err := s.repo.ListPlaces(ctx, userID)
+	if err != nil {
+		s.logger.Errorw("failed to list places", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to list places"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"places": places})
+}
+
+func (s *server) handleUpdatePlace(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+	placeID := mux.Vars(r)["placeId"]
+	if _, err := uuid.Parse(placeID); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "placeId must be a UUID"})
+		return
+	}
+	req, ok := decodePlaceRequest(w, r)
+	if !ok {
+		return
+	}
+	place, err := req.place(userID, placeID)
+	if err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	updated, err := s.repo.UpdatePlace(ctx, place)
+	if err != nil {
+		s.writePlaceError(w, err)
+		return
+	}
+	if updated == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "place not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (s *server) handleDeletePlace(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+	placeID := mux.Vars(r)["placeId"]
+	if _, err := uuid.Parse(placeID); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "placeId must be a UUID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	deleted, err := s.repo.DeletePlace(ctx, userID, placeID)
+	if err != nil {
+		s.logger.Errorw("failed to delete place", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to delete place"})
+		return
+	}
+	if !deleted {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "place not found"})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) writePlaceError(w http.ResponseWriter, err error) {
+	if errors.Is(err, repository.ErrPlaceNameTaken) {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		return
+	}
+	s.logger.Errorw("failed to save place", "error", err)
+	writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save place"})
+}
+
+func pathUserID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID := mux.Vars(r)["userId"]
+	if _, err := uuid.Parse(userID); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "userId must be a UUID"})
+		return "", false
+	}
+	return userID, true
+}
+
+func decodePlaceRequest(w http.ResponseWriter, r *http.Request) (placeRequest, bool) {
+	var req placeRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return placeRequest{}, false
+	}
+	return req, true
+}
+
+// tagPlace는 좌표가 사용자 장소 안에 있는 위치 이벤트의 방문을 기록하고 geo_context를 채웁니다.
+// 여러 장소에 포함되면 가장 좁은 장소를 사용한다(예: 쇼핑몰 안의 헬스장).
+func (p *eventProcessor) tagPlace(ctx context.Context, entry *repository.Entry) error {
+	lat, latOK := entry.Metadata["latitude"].(float64)
+	lng, lngOK := entry.Metadata["longitude"].(float64)
+	if !latOK || !lngOK {
+		// 위치 이벤트가 아니거나, 복호화되지 않은 좌표다.
+		return nil
+	}
+
+	places, err := p.repo.ListPlaces(ctx, entry.UserID)
+	if err != nil {
+		return err
+	}
+
+	point := geofence.Point{Lat: lat, Lng: lng}
+	var (
+		match *repository.Place
+		area  float64
+	)
+	for i := range places {
+		boundary := places[i].Boundary()
+		if !boundary.Contains(point) {
+			continue
+		}
+		if a := boundary.Area(); match == nil || a < area {
+			match, area = &places[i], a
+		}
+	}
+	if match == nil {
+		return nil
+	}
+
+	visit, err := p.repo.RecordVisit(ctx, *match, entry.StartedAt, entry.EndedAt, p.visitGap)
+	if err != nil {
+		return err
+	}
+	entry.GeoContext = visit.GeoContext()
+	return nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"daylog/services/common/messaging"
+	"daylog/services/timeline/repository"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// errEventPurged는 동의 철회로 원본이 삭제된 뒤 늦게 도착한 이벤트입니다. DLQ로 보내지 않고 버린다.
+var errEventPurged = errors.New("activity event was purged after consent revocation")
+
+// consentPurge는 수집 서비스가 동의 철회로 원본 이벤트를 삭제한 뒤 발행하는 activity.purge 메시지입니다.
+type consentPurge struct {
+	UserID      string    `json:"user_id"`
+	Kind        string    `json:"kind"`
+	Sources     []string  `json:"sources"`
+	Purged      int64     `json:"purged"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// purgeConsumer는 activity.purge를 소비해 삭제된 원본 이벤트에서 파생된 타임라인 데이터를 정리합니다.
+// 정리하지 못한 메시지는 커밋하지 않고 성공할 때까지 다시 시도한다.
+type purgeConsumer struct {
+	logger    *zap.SugaredLogger
+	consumer  *messaging.Consumer
+	processor *eventProcessor
+	policy    retryPolicy
+}
+
+// Run은 ctx가 취소될 때까지 메시지를 하나씩 처리하고 커밋합니다.
+func (c *purgeConsumer) Run(ctx context.Context) {
+	c.logger.Infow("starting timeline purge consumer")
+	for {
+		msg, err := c.consumer.Fetch(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				c.logger.Infow("timeline purge consumer stopped")
+				return
+			}
+			c.logger.Errorw("failed to fetch purge message", "error", err)
+			if !sleepContext(ctx, time.Second) {
+				return
+			}
+			continue
+		}
+
+		if !c.handle(ctx, msg) {
+			return
+		}
+		if err := c.consumer.Commit(ctx, msg); err != nil && ctx.Err() == nil {
+			c.logger.Errorw("failed to commit purge message", "offset", msg.Offset, "error", err)
+		}
+	}
+}
+
+// handle은 msg를 처리합니다. 종료로 처리를 마치지 못하면 false를 반환합니다.
+func (c *purgeConsumer) handle(ctx context.Context, msg kafka.Message) bool {
+	var req consentPurge
+	if err := json.Unmarshal(msg.Value, &req); err != nil {
+		c.logger.Errorw("skipping malformed purge message", "offset", msg.Offset, "error", err)
+		return true
+	}
+	if _, err := uuid.Parse(req.UserID); err != nil {
+		c.logger.Errorw("skipping purge message without valid user_id", "offset", msg.Offset, "error", err)
+		return true
+	}
+
+	for attempt := 1; ; attempt++ {
+		deleted, err := c.processor.purge(ctx, req.UserID)
+		if err == nil {
+			c.logger.Infow("purged derived timeline data",
+				"user_id", req.UserID,
+				"kind", req.Kind,
+				"purged_events", req.Purged,
+				"deleted_entries", deleted,
+			)
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+		delay := c.policy.delay(attempt)
+		c.logger.Warnw("failed to purge derived timeline data, retrying",
+			"user_id", req.UserID,
+			"attempt", attempt,
+			"retry_in", delay.String(),
+			"error", err,
+		)
+		if !sleepContext(ctx, delay) {
+			return false
+		}
+	}
+}
+
+// purge는 원본 이벤트가 삭제된 사용자의 타임라인 항목을 지우고, 항목에 남은 이벤트로 블록을 다시 만듭니다.
+// 삭제한 항목 수를 반환합니다.
+func (p *eventProcessor) purge(ctx context.Context, userID string) (int64, error) {
+	timelineIDs, remaining, err := p.repo.OrphanedEntries(ctx, userID)
+	if err != nil || len(timelineIDs) == 0 {
+		return 0, err
+	}
+
+	// 남은 이벤트는 여러 개가 같은 블록으로 다시 합쳐질 수 있으므로 이미 블록에 들어간 이벤트는 건너뛴다.
+	blocks := make([]repository.Entry, 0, len(remaining))
+	rebuilt := make(map[string]bool, len(remaining))
+	for _, evt := range remaining {
+		if rebuilt[evt.EventID] {
+			continue
+		}
+		block, err := p.prepare(ctx, evt)
+		if err != nil {
+			return 0, fmt.Errorf("rebuild timeline block for %s: %w", evt.EventID, err)
+		}
+		for _, id := range block.SourceEvents {
+			rebuilt[id] = true
+		}
+		blocks = append(blocks, block)
+	}
+	return p.repo.ReplaceEntries(ctx, userID, timelineIDs, blocks)
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrEntryNotFound는 사용자의 타임라인 항목이 없을 때 반환됩니다.
+var ErrEntryNotFound = errors.New("timeline entry not found")
+
+// Feedback은 activity_feedback 테이블의 카테고리 수정 기록입니다.
+// StartedAt 이하 필드는 학습에 쓰도록 수정 시점의 항목 정보를 함께 담으며 테이블에는 저장하지 않는다.
+type Feedback struct {
+	FeedbackID   string    `json:"feedback_id"`
+	TimelineID   string    `json:"timeline_id"`
+	UserID       string    `json:"user_id"`
+	OldCategory  string    `json:"old_category"`
+	NewCategory  string    `json:"new_category"`
+	Note         string    `json:"note,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	StartedAt    time.Time `json:"started_at"`
+	EndedAt      time.Time `json:"ended_at"`
+	SourceEvents []string  `json:"source_event_ids"`
+	ModelVersion string    `json:"model_version,omitempty"`
+}
+
+// RecordFeedback은 수정 기록을 저장하고 항목의 카테고리를 사용자 수정값(신뢰도 VerifiedConfidence, user_verified)으로 바꿉니다.
+// OldCategory는 저장된 항목에서 읽어 채운다. fb.UserID의 항목이 아니면 ErrEntryNotFound를 반환합니다.
+func (r *Repository) RecordFeedback(ctx context.Context, fb Feedback) (Feedback, error) {
+	if r == nil || r.pool == nil {
+		return Feedback{}, fmt.Errorf("timeline repository not initialised")
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return Feedback{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // nolint:errcheck
+
+	// 같은 항목을 다시 저장하는 소비자와 순서를 맞추기 위해 항목을 잠근다.
+	const lookup = `
+		SELECT category,
+		       started_at,
+		       ended_at,
+		       source_event_ids::text[],
+		       COALESCE(model_version, '')
+		  FROM timeline_entries
+		 WHERE timeline_id = $1
+		   AND user_id = $2
+		   FOR UPDATE
+	`
+	err = tx.QueryRow(ctx, lookup, fb.TimelineID, fb.UserID).Scan(
+		&fb.OldCategory,
+		&fb.StartedAt,
+		&fb.EndedAt,
+		&fb.SourceEvents,
+		&fb.ModelVersion,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Feedback{}, ErrEntryNotFound
+	}
+	if err != nil {
+		return Feedback{}, fmt.Errorf("query timeline entry: %w", err)
+	}
+
+	const insert = `
+		INSERT INTO activity_feedback (feedback_id, timeline_id, user_id, old_category, new_category, note)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))
+		RETURNING created_at
+	`
+	if err := tx.QueryRow(ctx, insert, fb.FeedbackID, fb.TimelineID, fb.UserID, fb.OldCategory, fb.NewCategory, fb.Note).Scan(&fb.CreatedAt); err != nil {
+		return Feedback{}, fmt.Errorf("insert activity feedback: %w", err)
+	}
+
+	const update = `
+		UPDATE timeline_entries
+		   SET category = $2,
+		       confidence = $3,
+		       user_verified = TRUE,
+		       updated_at = NOW()
+		 WHERE timeline_id = $1
+	`
+	if _, err := tx.Exec(ctx, update, fb.TimelineID, fb.NewCategory, VerifiedConfidence); err != nil {
+		return Feedback{}, fmt.Errorf("update timeline entry category: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Feedback{}, fmt.Errorf("commit transaction: %w", err)
+	}
+	return fb, nil
+}
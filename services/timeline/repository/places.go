@@ -0,0 +1,348 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"daylog/services/timeline/geofence"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrPlaceNameTaken은 같은 사용자에게 같은 이름의 장소가 이미 있을 때 반환됩니다.
+var ErrPlaceNameTaken = errors.New("place name already in use")
+
+// Place는 user_places 테이블의 사용자 장소입니다. 원은 Center/RadiusM, 다각형은 Polygon을 사용합니다.
+type Place struct {
+	PlaceID   string           `json:"place_id"`
+	UserID    string           `json:"user_id"`
+	Name      string           `json:"name"`
+	Shape     string           `json:"shape"`
+	Center    *geofence.Point  `json:"center,omitempty"`
+	RadiusM   float64          `json:"radius_m,omitempty"`
+	Polygon   []geofence.Point `json:"polygon,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// Boundary는 포함 여부 판정에 사용하는 장소 경계입니다.
+func (p Place) Boundary() geofence.Shape {
+	shape := geofence.Shape{Kind: p.Shape, RadiusM: p.RadiusM, Polygon: p.Polygon}
+	if p.Center != nil {
+		shape.Center = *p.Center
+	}
+	return shape
+}
+
+// Visit는 place_visits 테이블의 장소 방문입니다. 같은 장소에서 연속으로 들어온 위치 이벤트가 하나의 방문이 됩니다.
+type Visit struct {
+	VisitID   string
+	UserID    string
+	PlaceID   string
+	PlaceName string
+	EnteredAt time.Time
+	ExitedAt  time.Time
+}
+
+// GeoContext는 타임라인 항목의 geo_context로 저장되는 방문 정보입니다.
+func (v Visit) GeoContext() map[string]any {
+	return map[string]any{
+		"place_id":      v.PlaceID,
+		"place_name":    v.PlaceName,
+		"visit_id":      v.VisitID,
+		"entered_at":    v.EnteredAt.UTC().Format(time.RFC3339),
+		"exited_at":     v.ExitedAt.UTC().Format(time.RFC3339),
+		"dwell_seconds": int64(v.ExitedAt.Sub(v.EnteredAt).Seconds()),
+	}
+}
+
+// CreatePlace는 장소를 저장합니다. 이름이 중복되면 ErrPlaceNameTaken을 반환합니다.
+func (r *Repository) CreatePlace(ctx context.Context, place Place) (Place, error) {
+	if r == nil || r.pool == nil {
+		return Place{}, fmt.Errorf("timeline repository not initialised")
+	}
+
+	polygonJSON, err := marshalPolygon(place.Polygon)
+	if err != nil {
+		return Place{}, err
+	}
+
+	const query = `
+		INSERT INTO user_places (
+			place_id,
+			user_id,
+			name,
+			shape,
+			center_lat,
+			center_lng,
+			radius_m,
+			polygon
+		) VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7::double precision, 0), $8)
+		RETURNING created_at, updated_at
+	`
+
+	lat, lng := centerArgs(place.Center)
+	if err := r.pool.QueryRow(ctx, query,
+		place.PlaceID,
+		place.UserID,
+		place.Name,
+		place.Shape,
+		lat,
+		lng,
+		place.RadiusM,
+		polygonJSON,
+	).Scan(&place.CreatedAt, &place.UpdatedAt); err != nil {
+		if isUniqueViolation(err) {
+			return Place{}, ErrPlaceNameTaken
+		}
+		return Place{}, fmt.Errorf("insert place: %w", err)
+	}
+	return place, nil
+}
+
+// ListPlaces는 사용자의 장소를 이름 순으로 반환합니다.
+func (r *Repository) ListPlaces(ctx context.Context, userID string) ([]Place, error) {
+	if r == nil || r.pool == nil {
+		return nil, fmt.Errorf("timeline repository not initialised")
+	}
+
+	const query = `
+		SELECT place_id,
+		       user_id,
+		       name,
+		       shape,
+		       center_lat,
+		       center_lng,
+		       COALESCE(radius_m, 0),
+		       polygon,
+		       created_at,
+		       updated_at
+		  FROM user_places
+		 WHERE user_id = $1
+		 ORDER BY name
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query places: %w", err)
+	}
+	defer rows.Close()
+
+	places := []Place{}
+	for rows.Next() {
+		var (
+			p           Place
+			lat, lng    *float64
+			polygonJSON []byte
+		)
+		if err := rows.Scan(
+			&p.PlaceID,
+			&p.UserID,
+			&p.Name,
+			&p.Shape,
+			&lat,
+			&lng,
+			&p.RadiusM,
+			&polygonJSON,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan place row: %w", err)
+		}
+		if lat != nil && lng != nil {
+			p.Center = &geofence.Point{Lat: *lat, Lng: *lng}
+		}
+		if len(polygonJSON) > 0 {
+			if err := json.Unmarshal(polygonJSON, &p.Polygon); err != nil {
+				return nil, fmt.Errorf("unmarshal polygon: %w", err)
+			}
+		}
+		places = append(places, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate places: %w", err)
+	}
+	return places, nil
+}
+
+// UpdatePlace는 장소의 이름과 경계를 바꿉니다. 장소가 없으면 nil을 반환합니다.
+// 이미 기록된 방문과 타임라인 항목의 geo_context는 다시 계산하지 않는다.
+func (r *Repository) UpdatePlace(ctx context.Context, place Place) (*Place, error) {
+	if r == nil || r.pool == nil {
+		return nil, fmt.Errorf("timeline repository not initialised")
+	}
+
+	polygonJSON, err := marshalPolygon(place.Polygon)
+	if err != nil {
+		return nil, err
+	}
+
+	const query = `
+		UPDATE user_places
+		   SET name = $3,
+		       shape = $4,
+		       center_lat = $5,
+		       center_lng = $6,
+		       radius_m = NULLIF($7::double precision, 0),
+		       polygon = $8,
+		       updated_at = NOW()
+		 WHERE user_id = $1
+		   AND place_id = $2
+		RETURNING created_at, updated_at
+	`
+
+	lat, lng := centerArgs(place.Center)
+	err = r.pool.QueryRow(ctx, query,
+		place.UserID,
+		place.PlaceID,
+		place.Name,
+		place.Shape,
+		lat,
+		lng,
+		place.RadiusM,
+		polygonJSON,
+	).Scan(&place.CreatedAt, &place.UpdatedAt)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, nil
+	case isUniqueViolation(err):
+		return nil, ErrPlaceNameTaken
+	case err != nil:
+		return nil, fmt.Errorf("update place: %w", err)
+	}
+	return &place, nil
+}
+
+// DeletePlace는 장소와 그 방문 기록을 삭제합니다. 삭제된 장소가 없으면 false를 반환합니다.
+func (r *Repository) DeletePlace(ctx context.Context, userID, placeID string) (bool, error) {
+	if r == nil || r.pool == nil {
+		return false, fmt.Errorf("timeline repository not initialised")
+	}
+
+	const query = `DELETE FROM user_places WHERE user_id = $1 AND place_id = $2`
+
+	ct, err := r.pool.Exec(ctx, query, userID, placeID)
+	if err != nil {
+		return false, fmt.Errorf("delete place: %w", err)
+	}
+	return ct.RowsAffected() > 0, nil
+}
+
+// RecordVisit는 [start, end] 동안 장소에 있었음을 기록합니다.
+// 같은 장소의 기존 방문과 gap 이내로 이어지면 그 방문을 늘리고, 아니면 새 방문을 만든다.
+// 방문이 늘어나면 이미 저장된 같은 방문의 타임라인 항목 geo_context도 함께 갱신한다.
+func (r *Repository) RecordVisit(ctx context.Context, place Place, start, end time.Time, gap time.Duration) (Visit, error) {
+	if r == nil || r.pool == nil {
+		return Visit{}, fmt.Errorf("timeline repository not initialised")
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return Visit{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // nolint:errcheck
+
+	const lookup = `
+		SELECT visit_id,
+		       entered_at,
+		       exited_at
+		  FROM place_visits
+		 WHERE user_id = $1
+		   AND place_id = $2
+		   AND entered_at <= $4
+		   AND exited_at >= $3
+		 ORDER BY entered_at DESC
+		 LIMIT 1
+		   FOR UPDATE
+	`
+
+	visit := Visit{UserID: place.UserID, PlaceID: place.PlaceID, PlaceName: place.Name}
+	err = tx.QueryRow(ctx, lookup, place.UserID, place.PlaceID, start.Add(-gap), end.Add(gap)).
+		Scan(&visit.VisitID, &visit.EnteredAt, &visit.ExitedAt)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		visit.VisitID = uuid.NewString()
+		visit.EnteredAt, visit.ExitedAt = start, end
+		const insert = `
+			INSERT INTO place_visits (
+				visit_id,
+				user_id,
+				place_id,
+				entered_at,
+				exited_at
+			) VALUES ($1, $2, $3, $4, $5)
+		`
+		if _, err := tx.Exec(ctx, insert, visit.VisitID, visit.UserID, visit.PlaceID, start, end); err != nil {
+			return Visit{}, fmt.Errorf("insert place visit: %w", err)
+		}
+	case err != nil:
+		return Visit{}, fmt.Errorf("lookup place visit: %w", err)
+	default:
+		if !start.Before(visit.EnteredAt) && !end.After(visit.ExitedAt) {
+			// 이미 방문 구간 안에 있는 이벤트(재전송 등)는 갱신할 것이 없다.
+			return visit, tx.Commit(ctx)
+		}
+		if start.Before(visit.EnteredAt) {
+			visit.EnteredAt = start
+		}
+		if end.After(visit.ExitedAt) {
+			visit.ExitedAt = end
+		}
+		const extend = `
+			UPDATE place_visits
+			   SET entered_at = $2,
+			       exited_at = $3
+			 WHERE visit_id = $1
+		`
+		if _, err := tx.Exec(ctx, extend, visit.VisitID, visit.EnteredAt, visit.ExitedAt); err != nil {
+			return Visit{}, fmt.Errorf("extend place visit: %w", err)
+		}
+
+		geoJSON, err := json.Marshal(visit.GeoContext())
+		if err != nil {
+			return Visit{}, fmt.Errorf("marshal geo context: %w", err)
+		}
+		const refresh = `
+			UPDATE timeline_entries
+			   SET geo_context = $3
+			 WHERE user_id = $1
+			   AND geo_context->>'visit_id' = $2
+		`
+		if _, err := tx.Exec(ctx, refresh, visit.UserID, visit.VisitID, geoJSON); err != nil {
+			return Visit{}, fmt.Errorf("refresh visit geo context: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Visit{}, fmt.Errorf("commit transaction: %w", err)
+	}
+	return visit, nil
+}
+
+func centerArgs(center *geofence.Point) (lat, lng *float64) {
+	if center == nil {
+		return nil, nil
+	}
+	return &center.Lat, &center.Lng
+}
+
+func marshalPolygon(polygon []geofence.Point) ([]byte, error) {
+	if len(polygon) == 0 {
+		return nil, nil
+	}
+	raw, err := json.Marshal(polygon)
+	if err != nil {
+		return nil, fmt.Errorf("marshal polygon: %w", err)
+	}
+	return raw, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
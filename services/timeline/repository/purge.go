@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+// OrphanedEntries는 원본 이벤트(activity_events) 일부가 삭제된 사용자의 타임라인 항목과,
+// 그 항목에 남아 있는 이벤트를 시작 시각 순으로 반환합니다. 동의 철회로 원본을 삭제한 뒤 파생 데이터를 정리할 때 사용한다.
+func (r *Repository) OrphanedEntries(ctx context.Context, userID string) ([]string, []Entry, error) {
+	if r == nil || r.pool == nil {
+		return nil, nil, fmt.Errorf("timeline repository not initialised")
+	}
+
+	const orphaned = `
+		SELECT t.timeline_id::text
+		  FROM timeline_entries t
+		 WHERE t.user_id = $1
+		   AND EXISTS (
+		       SELECT 1
+		         FROM unnest(t.source_event_ids) AS s(event_id)
+		        WHERE NOT EXISTS (SELECT 1 FROM activity_events a WHERE a.event_id = s.event_id)
+		   )
+	`
+
+	rows, err := r.pool.Query(ctx, orphaned, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("query orphaned timeline_entries: %w", err)
+	}
+	var timelineIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, nil, fmt.Errorf("scan orphaned timeline entry: %w", err)
+		}
+		timelineIDs = append(timelineIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("iterate orphaned timeline_entries: %w", err)
+	}
+	if len(timelineIDs) == 0 {
+		return nil, nil, nil
+	}
+
+	const remaining = `
+		SELECT event_id,
+		       user_id,
+		       source,
+		       timestamp_start,
+		       timestamp_end,
+		       metadata,
+		       COALESCE(device_id::text, '')
+		  FROM activity_events
+		 WHERE user_id = $1
+		   AND event_id IN (
+		       SELECT unnest(source_event_ids)
+		         FROM timeline_entries
+		        WHERE timeline_id = ANY($2::text[]::uuid[])
+		   )
+		 ORDER BY timestamp_start, event_id
+	`
+
+	eventRows, err := r.pool.Query(ctx, remaining, userID, timelineIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("query remaining activity_events: %w", err)
+	}
+	defer eventRows.Close()
+
+	events, err := r.scanActivityEvents(ctx, eventRows, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return timelineIDs, events, nil
+}
+
+// ReplaceEntries는 timelineIDs 항목과 그 수정 기록을 삭제하고 blocks를 저장한 뒤,
+// 삭제한 항목이 가리키던 장소 방문 중 더 이상 어떤 항목도 가리키지 않는 방문을 삭제합니다. 모두 한 트랜잭션으로 처리하며 삭제한 항목 수를 반환합니다.
+// 사용자가 수정한 카테고리는 삭제된 데이터를 보고 내린 판단이므로 다시 만든 블록으로 옮기지 않는다.
+func (r *Repository) ReplaceEntries(ctx context.Context, userID string, timelineIDs []string, blocks []Entry) (int64, error) {
+	if r == nil || r.pool == nil {
+		return 0, fmt.Errorf("timeline repository not initialised")
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // nolint:errcheck
+
+	const visits = `
+		SELECT DISTINCT geo_context->>'visit_id'
+		  FROM timeline_entries
+		 WHERE user_id = $1
+		   AND timeline_id = ANY($2::text[]::uuid[])
+		   AND geo_context ? 'visit_id'
+	`
+	rows, err := tx.Query(ctx, visits, userID, timelineIDs)
+	if err != nil {
+		return 0, fmt.Errorf("query purged place visits: %w", err)
+	}
+	var visitIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan purged place visit: %w", err)
+		}
+		visitIDs = append(visitIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterate purged place visits: %w", err)
+	}
+
+	const feedback = `DELETE FROM activity_feedback WHERE user_id = $1 AND timeline_id = ANY($2::text[]::uuid[])`
+	if _, err := tx.Exec(ctx, feedback, userID, timelineIDs); err != nil {
+		return 0, fmt.Errorf("delete purged activity feedback: %w", err)
+	}
+	const entries = `DELETE FROM timeline_entries WHERE user_id = $1 AND timeline_id = ANY($2::text[]::uuid[])`
+	ct, err := tx.Exec(ctx, entries, userID, timelineIDs)
+	if err != nil {
+		return 0, fmt.Errorf("delete purged timeline entries: %w", err)
+	}
+
+	for _, block := range blocks {
+		if err := mergeEntry(ctx, tx, block); err != nil {
+			return 0, err
+		}
+	}
+
+	if len(visitIDs) > 0 {
+		const unreferenced = `
+			DELETE FROM place_visits v
+			 WHERE v.user_id = $1
+			   AND v.visit_id = ANY($2::text[]::uuid[])
+			   AND NOT EXISTS (
+			       SELECT 1
+			         FROM timeline_entries t
+			        WHERE t.user_id = $1
+			          AND t.geo_context->>'visit_id' = v.visit_id::text
+			   )
+		`
+		if _, err := tx.Exec(ctx, unreferenced, userID, visitIDs); err != nil {
+			return 0, fmt.Errorf("delete purged place visits: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit transaction: %w", err)
+	}
+	return ct.RowsAffected(), nil
+}
+
+// SourcePurged는 사용자가 동의를 철회하며 source의 원본 이벤트 삭제를 요청한 적이 있는지 확인합니다.
+// 삭제 전에 발행되어 늦게 도착한 이벤트로 타임라인 항목이 다시 만들어지지 않도록 소비자가 사용한다.
+func (r *Repository) SourcePurged(ctx context.Context, userID, source string) (bool, error) {
+	if r == nil || r.pool == nil {
+		return false, fmt.Errorf("timeline repository not initialised")
+	}
+
+	const query = `
+		SELECT EXISTS (
+		       SELECT 1
+		         FROM consent_purges
+		        WHERE user_id = $1
+		          AND $2 = ANY(sources)
+		)
+	`
+
+	var purged bool
+	if err := r.pool.QueryRow(ctx, query, userID, source).Scan(&purged); err != nil {
+		return false, fmt.Errorf("query consent_purges: %w", err)
+	}
+	return purged, nil
+}
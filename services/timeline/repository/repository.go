@@ -4,36 +4,211 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
+	"daylog/services/common/fieldcrypt"
+
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // Entry는 타임라인 응답에 사용되는 구조체입니다.
 type Entry struct {
-	EventID       string                 `json:"event_id"`
-	UserID        string                 `json:"user_id"`
-	Category      string                 `json:"category"`
-	StartedAt     time.Time              `json:"started_at"`
-	EndedAt       time.Time              `json:"ended_at"`
-	Confidence    float64                `json:"confidence"`
-	GeoContext    map[string]any         `json:"geo_context"`
-	Source        string                 `json:"source"`
-	Metadata      map[string]interface{} `json:"metadata"`
-	SourceEvents  []string               `json:"source_event_ids"`
+	EventID      string                 `json:"event_id"`
+	UserID       string                 `json:"user_id"`
+	Category     string                 `json:"category"`
+	StartedAt    time.Time              `json:"started_at"`
+	EndedAt      time.Time              `json:"ended_at"`
+	Confidence   float64                `json:"confidence"`
+	GeoContext   map[string]any         `json:"geo_context"`
+	Source       string                 `json:"source"`
+	Metadata     map[string]interface{} `json:"metadata"`
+	SourceEvents []string               `json:"source_event_ids"`
+	// DeviceID는 원본 이벤트를 보고한 기기입니다. 기기 인증 없이 수집된 이벤트는 비어 있으며, 타임라인 항목에는 저장하지 않는다.
+	DeviceID string `json:"-"`
+	// 분류 결과. 분류 서비스를 쓰지 못하면 ModelVersion은 RuleModelVersion이고 CategoryConfidence는 0이다.
+	CategoryConfidence float64  `json:"category_confidence"`
+	Rationale          []string `json:"rationale"`
+	ModelVersion       string   `json:"model_version"`
+	// UserVerified는 사용자가 카테고리를 수정한 항목입니다. 소비자가 다시 저장해도 카테고리와 신뢰도는 바뀌지 않는다.
+	UserVerified bool `json:"user_verified"`
+}
+
+// VerifiedConfidence는 사용자가 수정한 카테고리의 신뢰도입니다.
+const VerifiedConfidence = 1.0
+
+// RuleModelVersion은 분류 서비스 대신 규칙 기반 카테고리를 사용한 항목의 model_version입니다.
+const RuleModelVersion = "rules"
+
+// Block은 timeline_entries에 저장된 병합 블록입니다. Source는 블록 대표 이벤트의 소스이고,
+// UserVerified는 사용자가 카테고리를 수정했는지 여부입니다.
+type Block struct {
+	TimelineID   string         `json:"timeline_id"`
+	UserID       string         `json:"user_id"`
+	Category     string         `json:"category"`
+	UserVerified bool           `json:"user_verified"`
+	StartedAt    time.Time      `json:"started_at"`
+	EndedAt      time.Time      `json:"ended_at"`
+	Confidence   float64        `json:"confidence"`
+	Source       string         `json:"source"`
+	GeoContext   map[string]any `json:"geo_context"`
+	SourceEvents []string       `json:"source_event_ids"`
+	// CategoryConfidence와 Rationale은 분류 서비스 결과이며, 규칙 기반 카테고리면 비어 있다.
+	CategoryConfidence float64  `json:"category_confidence,omitempty"`
+	Rationale          []string `json:"rationale"`
+	ModelVersion       string   `json:"model_version"`
 }
 
 type Repository struct {
-	pool *pgxpool.Pool
+	pool  *pgxpool.Pool
+	crypt *fieldcrypt.Encryptor
 }
 
-func New(pool *pgxpool.Pool) *Repository {
-	return &Repository{pool: pool}
+// New는 Repository를 생성합니다. crypt가 nil이 아니면 암호화된 메타데이터 필드를 조회 시 복호화합니다.
+func New(pool *pgxpool.Pool, crypt *fieldcrypt.Encryptor) *Repository {
+	return &Repository{pool: pool, crypt: crypt}
 }
 
-// ListActivityEvents는 activity_events 테이블을 기반으로 타임라인을 구성합니다.
-func (r *Repository) ListActivityEvents(ctx context.Context, userID string, limit int) ([]Entry, error) {
+// blockSelect는 timeline_entries 블록 조회의 공통 SELECT 절입니다.
+const blockSelect = `
+		SELECT t.timeline_id::text,
+		       t.user_id::text,
+		       t.category,
+		       t.user_verified,
+		       t.started_at,
+		       t.ended_at,
+		       t.confidence::float8,
+		       COALESCE(t.source, ''),
+		       t.geo_context,
+		       t.source_event_ids::text[],
+		       COALESCE(t.category_confidence, 0)::float8,
+		       t.rationale,
+		       COALESCE(t.model_version, '')
+		  FROM timeline_entries t
+`
+
+// TimelineCursor는 키셋 페이지네이션 위치입니다. 정렬 순서(started_at, timeline_id 내림차순)에서 이 블록 다음부터 조회한다.
+type TimelineCursor struct {
+	StartedAt  time.Time
+	TimelineID string
+}
+
+// TimelineQuery는 ListTimeline 조회 조건입니다. 값이 비어 있는 조건은 적용하지 않습니다.
+type TimelineQuery struct {
+	Limit      int
+	Cursor     *TimelineCursor
+	Before     time.Time // started_at < Before
+	After      time.Time // started_at >= After
+	Categories []string
+}
+
+// ListTimeline은 timeline_entries에 저장된 사용자의 블록을 최신순(started_at, timeline_id 내림차순)으로 반환합니다.
+// (user_id, started_at DESC, timeline_id DESC) 인덱스를 따라 읽으므로 오래된 기록까지 넘겨도 조회 비용이 일정하다.
+func (r *Repository) ListTimeline(ctx context.Context, userID string, q TimelineQuery) ([]Block, error) {
+	if r == nil || r.pool == nil {
+		return nil, fmt.Errorf("timeline repository not initialised")
+	}
+
+	var (
+		where = []string{"t.user_id = $1", "t.started_at IS NOT NULL"}
+		args  = []any{userID}
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if q.Cursor != nil {
+		where = append(where, fmt.Sprintf("(t.started_at, t.timeline_id) < (%s, %s::uuid)", arg(q.Cursor.StartedAt), arg(q.Cursor.TimelineID)))
+	}
+	if !q.Before.IsZero() {
+		where = append(where, "t.started_at < "+arg(q.Before))
+	}
+	if !q.After.IsZero() {
+		where = append(where, "t.started_at >= "+arg(q.After))
+	}
+	if len(q.Categories) > 0 {
+		where = append(where, fmt.Sprintf("t.category = ANY(%s::text[])", arg(q.Categories)))
+	}
+
+	query := blockSelect + `
+		 WHERE ` + strings.Join(where, "\n		   AND ") + `
+		 ORDER BY t.started_at DESC, t.timeline_id DESC
+		 LIMIT ` + arg(q.Limit)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query timeline_entries: %w", err)
+	}
+	defer rows.Close()
+
+	return scanBlocks(rows, q.Limit)
+}
+
+// BlocksBetween은 [from, to)와 겹치는 사용자의 블록을 시작 시각 순으로 반환합니다.
+func (r *Repository) BlocksBetween(ctx context.Context, userID string, from, to time.Time) ([]Block, error) {
+	if r == nil || r.pool == nil {
+		return nil, fmt.Errorf("timeline repository not initialised")
+	}
+
+	const query = blockSelect + `
+		 WHERE t.user_id = $1
+		   AND t.started_at < $3
+		   AND t.ended_at > $2
+		 ORDER BY t.started_at, t.timeline_id
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("query timeline_entries between: %w", err)
+	}
+	defer rows.Close()
+
+	return scanBlocks(rows, 0)
+}
+
+func scanBlocks(rows pgx.Rows, capacity int) ([]Block, error) {
+	blocks := make([]Block, 0, capacity)
+	for rows.Next() {
+		var (
+			block   Block
+			geoJSON []byte
+		)
+		if err := rows.Scan(
+			&block.TimelineID,
+			&block.UserID,
+			&block.Category,
+			&block.UserVerified,
+			&block.StartedAt,
+			&block.EndedAt,
+			&block.Confidence,
+			&block.Source,
+			&geoJSON,
+			&block.SourceEvents,
+			&block.CategoryConfidence,
+			&block.Rationale,
+			&block.ModelVersion,
+		); err != nil {
+			return nil, fmt.Errorf("scan timeline_entries row: %w", err)
+		}
+		block.GeoContext = map[string]any{}
+		if len(geoJSON) > 0 {
+			if err := json.Unmarshal(geoJSON, &block.GeoContext); err != nil {
+				return nil, fmt.Errorf("unmarshal geo context: %w", err)
+			}
+		}
+		blocks = append(blocks, block)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate timeline_entries: %w", err)
+	}
+	return blocks, nil
+}
+
+// OverlappingEvents는 사용자의 이벤트 중 [start, end] 구간과 겹치고 from 이후에 시작한 이벤트를 반환합니다.
+// from은 조회 범위를 (user_id, timestamp_start) 인덱스로 좁히기 위한 시작 시각 하한입니다.
+func (r *Repository) OverlappingEvents(ctx context.Context, userID string, from, start, end time.Time) ([]Entry, error) {
 	if r == nil || r.pool == nil {
 		return nil, fmt.Errorf("timeline repository not initialised")
 	}
@@ -44,20 +219,64 @@ func (r *Repository) ListActivityEvents(ctx context.Context, userID string, limi
 		       source,
 		       timestamp_start,
 		       timestamp_end,
-		       metadata
+		       metadata,
+		       COALESCE(device_id::text, '')
 		  FROM activity_events
 		 WHERE user_id = $1
-		 ORDER BY timestamp_start DESC
-		 LIMIT $2
+		   AND timestamp_start >= $2
+		   AND timestamp_start <= $4
+		   AND timestamp_end >= $3
+		 ORDER BY timestamp_start, event_id
 	`
 
-	rows, err := r.pool.Query(ctx, query, userID, limit)
+	rows, err := r.pool.Query(ctx, query, userID, from, start, end)
 	if err != nil {
-		return nil, fmt.Errorf("query activity_events: %w", err)
+		return nil, fmt.Errorf("query overlapping activity_events: %w", err)
 	}
 	defer rows.Close()
 
-	entries := make([]Entry, 0, limit)
+	return r.scanActivityEvents(ctx, rows, 0)
+}
+
+// RelatedEvents는 eventIDs 중 하나를 포함하는 기존 타임라인 항목에 속한 이벤트 중 eventIDs에 없는 이벤트를 반환합니다.
+// 조회 범위 밖까지 이어진 블록을 다시 병합할 때 블록이 잘리지 않도록 사용한다.
+func (r *Repository) RelatedEvents(ctx context.Context, userID string, eventIDs []string) ([]Entry, error) {
+	if r == nil || r.pool == nil {
+		return nil, fmt.Errorf("timeline repository not initialised")
+	}
+
+	const query = `
+		SELECT event_id,
+		       user_id,
+		       source,
+		       timestamp_start,
+		       timestamp_end,
+		       metadata,
+		       COALESCE(device_id::text, '')
+		  FROM activity_events
+		 WHERE user_id = $1
+		   AND event_id IN (
+		       SELECT unnest(source_event_ids)
+		         FROM timeline_entries
+		        WHERE user_id = $1
+		          AND source_event_ids && $2::text[]::uuid[]
+		   )
+		   AND event_id <> ALL($2::text[]::uuid[])
+		 ORDER BY timestamp_start, event_id
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, eventIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query related activity_events: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanActivityEvents(ctx, rows, 0)
+}
+
+// scanActivityEvents는 activity_events 조회 결과를 Entry로 변환하고 암호화된 메타데이터 필드를 복호화합니다.
+func (r *Repository) scanActivityEvents(ctx context.Context, rows pgx.Rows, capacity int) ([]Entry, error) {
+	entries := make([]Entry, 0, capacity)
 	for rows.Next() {
 		var (
 			entry        Entry
@@ -70,6 +289,7 @@ func (r *Repository) ListActivityEvents(ctx context.Context, userID string, limi
 			&entry.StartedAt,
 			&entry.EndedAt,
 			&metadataJSON,
+			&entry.DeviceID,
 		); err != nil {
 			return nil, fmt.Errorf("scan activity_events row: %w", err)
 		}
@@ -81,6 +301,12 @@ func (r *Repository) ListActivityEvents(ctx context.Context, userID string, limi
 		} else {
 			entry.Metadata = map[string]interface{}{}
 		}
+		if r.crypt != nil {
+			// 데이터 키가 파기된 필드는 DecryptFields가 제거한다.
+			if _, err := r.crypt.DecryptFields(ctx, entry.UserID, entry.Metadata); err != nil {
+				return nil, fmt.Errorf("decrypt metadata: %w", err)
+			}
+		}
 
 		entry.Category = deriveCategory(entry)
 		entry.Confidence = deriveConfidence(entry)
@@ -130,11 +356,124 @@ func (r *Repository) UpsertTimelineEntry(ctx context.Context, entry Entry) error
 	if r == nil || r.pool == nil {
 		return fmt.Errorf("timeline repository not initialised")
 	}
+	return upsertTimelineEntry(ctx, r.pool, entry)
+}
+
+// MergeEntries는 병합된 블록 항목들을 한 트랜잭션으로 순서대로 저장합니다.
+// 블록의 이벤트(block.SourceEvents)를 포함하는 기존 항목(늦게 도착한 이벤트로 블록의 첫 이벤트가 바뀐 경우 포함)은 삭제하고,
+// 그 source_event_ids와 사용자 수정 카테고리, 수정 기록을 블록 항목(timeline_id = block.EventID)으로 옮긴다.
+// 블록 하나라도 실패하면 모두 저장하지 않는다.
+func (r *Repository) MergeEntries(ctx context.Context, blocks ...Entry) error {
+	if r == nil || r.pool == nil {
+		return fmt.Errorf("timeline repository not initialised")
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // nolint:errcheck
+
+	for _, block := range blocks {
+		if err := mergeEntry(ctx, tx, block); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+func mergeEntry(ctx context.Context, tx pgx.Tx, block Entry) error {
+	const lookup = `
+		SELECT timeline_id::text,
+		       source_event_ids::text[],
+		       user_verified,
+		       category,
+		       COALESCE((SELECT MAX(f.created_at) FROM activity_feedback f WHERE f.timeline_id = t.timeline_id), t.updated_at)
+		  FROM timeline_entries t
+		 WHERE user_id = $1
+		   AND (timeline_id = ANY($2::text[]::uuid[]) OR source_event_ids && $2::text[]::uuid[])
+		   FOR UPDATE OF t
+	`
+
+	rows, err := tx.Query(ctx, lookup, block.UserID, block.SourceEvents)
+	if err != nil {
+		return fmt.Errorf("query merged timeline entries: %w", err)
+	}
+	var (
+		stale      []string
+		seen       = map[string]bool{}
+		merged     []string
+		verifiedAt time.Time
+	)
+	add := func(ids ...string) {
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				merged = append(merged, id)
+			}
+		}
+	}
+	add(block.SourceEvents...)
+	for rows.Next() {
+		var (
+			timelineID string
+			sourceIDs  []string
+			verified   bool
+			category   string
+			modifiedAt time.Time
+		)
+		if err := rows.Scan(&timelineID, &sourceIDs, &verified, &category, &modifiedAt); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan merged timeline entry: %w", err)
+		}
+		add(sourceIDs...)
+		// 사용자가 수정한 카테고리는 병합 뒤에도 유지한다. 여러 항목이 수정되었으면 가장 최근 수정을 따른다.
+		if verified && (!block.UserVerified || modifiedAt.After(verifiedAt)) {
+			block.Category, block.Confidence, block.UserVerified = category, VerifiedConfidence, true
+			verifiedAt = modifiedAt
+		}
+		if timelineID != block.EventID {
+			stale = append(stale, timelineID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate merged timeline entries: %w", err)
+	}
+
+	block.SourceEvents = merged
+	if err := upsertTimelineEntry(ctx, tx, block); err != nil {
+		return err
+	}
+
+	if len(stale) > 0 {
+		// 수정 기록이 삭제되는 항목을 참조하지 않도록 새 블록 항목으로 옮긴 뒤 삭제한다.
+		const move = `UPDATE activity_feedback SET timeline_id = $1 WHERE timeline_id = ANY($2::text[]::uuid[])`
+		if _, err := tx.Exec(ctx, move, block.EventID, stale); err != nil {
+			return fmt.Errorf("move activity feedback: %w", err)
+		}
+		const remove = `DELETE FROM timeline_entries WHERE timeline_id = ANY($1::text[]::uuid[])`
+		if _, err := tx.Exec(ctx, remove, stale); err != nil {
+			return fmt.Errorf("delete merged timeline entries: %w", err)
+		}
+	}
+	return nil
+}
+
+// execer는 *pgxpool.Pool과 pgx.Tx가 공통으로 제공하는 Exec입니다.
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
 
+func upsertTimelineEntry(ctx context.Context, db execer, entry Entry) error {
+	// geo_context는 NOT NULL이므로 장소 정보가 없으면 빈 객체를 저장한다.
 	var (
-		geoJSON  []byte
-		metaJSON []byte
-		err      error
+		geoJSON = []byte("{}")
+		err     error
 	)
 
 	if len(entry.GeoContext) > 0 {
@@ -143,11 +482,10 @@ func (r *Repository) UpsertTimelineEntry(ctx context.Context, entry Entry) error
 			return fmt.Errorf("marshal geo context: %w", err)
 		}
 	}
-	if len(entry.Metadata) > 0 {
-		metaJSON, err = json.Marshal(entry.Metadata)
-		if err != nil {
-			return fmt.Errorf("marshal metadata: %w", err)
-		}
+
+	rationale := entry.Rationale
+	if rationale == nil {
+		rationale = []string{}
 	}
 
 	const query = `
@@ -157,17 +495,32 @@ func (r *Repository) UpsertTimelineEntry(ctx context.Context, entry Entry) error
 			category,
 			confidence,
 			geo_context,
-			source_event_ids
-		) VALUES ($1, $2, $3, $4, $5, $6)
+			source_event_ids,
+			source,
+			started_at,
+			ended_at,
+			category_confidence,
+			rationale,
+			model_version,
+			user_verified
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10::double precision, 0), $11, $12, $13)
 		ON CONFLICT (timeline_id)
 		DO UPDATE SET
-			category = EXCLUDED.category,
-			confidence = EXCLUDED.confidence,
+			category = CASE WHEN timeline_entries.user_verified AND NOT EXCLUDED.user_verified THEN timeline_entries.category ELSE EXCLUDED.category END,
+			confidence = CASE WHEN timeline_entries.user_verified AND NOT EXCLUDED.user_verified THEN timeline_entries.confidence ELSE EXCLUDED.confidence END,
+			user_verified = timeline_entries.user_verified OR EXCLUDED.user_verified,
 			geo_context = EXCLUDED.geo_context,
-			source_event_ids = EXCLUDED.source_event_ids
+			source_event_ids = EXCLUDED.source_event_ids,
+			source = EXCLUDED.source,
+			started_at = EXCLUDED.started_at,
+			ended_at = EXCLUDED.ended_at,
+			category_confidence = EXCLUDED.category_confidence,
+			rationale = EXCLUDED.rationale,
+			model_version = EXCLUDED.model_version,
+			updated_at = NOW()
 	`
 
-	_, err = r.pool.Exec(
+	_, err = db.Exec(
 		ctx,
 		query,
 		entry.EventID,
@@ -176,6 +529,13 @@ func (r *Repository) UpsertTimelineEntry(ctx context.Context, entry Entry) error
 		entry.Confidence,
 		geoJSON,
 		entry.SourceEvents,
+		entry.Source,
+		entry.StartedAt,
+		entry.EndedAt,
+		entry.CategoryConfidence,
+		rationale,
+		entry.ModelVersion,
+		entry.UserVerified,
 	)
 	if err != nil {
 		return fmt.Errorf("upsert timeline entry: %w", err)
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// UserTimezone은 사용자의 user_settings.timezone을 반환합니다. 설정이 없으면 빈 문자열입니다.
+func (r *Repository) UserTimezone(ctx context.Context, userID string) (string, error) {
+	if r == nil || r.pool == nil {
+		return "", fmt.Errorf("timeline repository not initialised")
+	}
+
+	const query = `
+		SELECT timezone
+		  FROM user_settings
+		 WHERE user_id = $1
+	`
+
+	var timezone string
+	err := r.pool.QueryRow(ctx, query, userID).Scan(&timezone)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("query user timezone: %w", err)
+	}
+	return timezone, nil
+}